@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/benji/cogito/internal/app"
+	shellctx "github.com/benji/cogito/internal/context"
+)
+
+// runCommit implements "cogito commit": have the model write a message
+// for the staged changes, let the user edit or regenerate it, then run
+// git commit with it.
+func runCommit(args []string) int {
+	fs := flag.NewFlagSet("commit", flag.ContinueOnError)
+	profile := fs.String("profile", "", "use the settings of profile `name` from config.json")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "cogito commit takes no arguments. To ask a question starting with \"commit\", use cogito -p \"commit ...\".")
+		return 2
+	}
+
+	diff, stat, err := shellctx.StagedChanges()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	cfg, err := loadConfig(*profile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return 1
+	}
+
+	m := app.NewModel(cfg)
+	m.SetCommitPrompt(shellctx.BuildCommitPrompt(diff, stat))
+	final, err := runTUI(cfg, m)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	out := final.CommitOutput()
+	if out == "" {
+		fmt.Fprintln(os.Stderr, "Commit aborted.")
+		return 1
+	}
+	fmt.Print(out)
+	return 0
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/benji/cogito/internal/app"
+	shellctx "github.com/benji/cogito/internal/context"
+)
+
+// runFix implements "cogito fix": ask the model to repair the last failed
+// command. The command, exit status and stderr come from the shell-init
+// hook's environment unless given as flags; stderr may also be piped in.
+func runFix(args []string) int {
+	fs := flag.NewFlagSet("fix", flag.ContinueOnError)
+	command := fs.String("command", "", "the failed `command` line (default: $"+shellctx.EnvLastCommand+")")
+	status := fs.Int("status", -1, "its exit `status` (default: $"+shellctx.EnvLastStatus+")")
+	stderrFile := fs.String("stderr-file", "", "file holding the command's error output")
+	insertFile := fs.String("insert-file", "", "write the picked command to `path` and exit (used by shell-init widgets)")
+	profile := fs.String("profile", "", "use the settings of profile `name` from config.json")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "cogito fix takes no arguments. To ask a question starting with \"fix\", use cogito -p \"fix ...\".")
+		return 2
+	}
+
+	fc, fromEnv := shellctx.LastCommandFromEnv()
+	if *command != "" {
+		fromEnv = false
+		fc = shellctx.FailedCommand{Command: *command, Status: -1}
+	}
+	if *status >= 0 {
+		fc.Status = *status
+	}
+	if *stderrFile != "" {
+		data, err := os.ReadFile(*stderrFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		fc.Stderr = string(data)
+	}
+	if stdinIsPipe() {
+		data, _, err := readStdin()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading stdin: %v\n", err)
+			return 1
+		}
+		fc.Stderr = data
+	}
+	if strings.TrimSpace(fc.Command) == "" {
+		fmt.Fprintln(os.Stderr, "No command to fix. Load the shell hook (cogito shell-init) or pass --command.")
+		return 2
+	}
+	if fromEnv && !fc.Failed() {
+		fmt.Fprintln(os.Stderr, "Last command succeeded — nothing to fix.")
+		return 0
+	}
+
+	cfg, err := loadConfig(*profile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return 1
+	}
+
+	m := app.NewModel(cfg)
+	m.SetFixCommand(fc)
+	if *insertFile != "" {
+		m.SetInsertFile(*insertFile)
+	}
+	if _, err := runTUI(cfg, m); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -3,6 +3,8 @@ package app
 import (
 	"context"
 	"fmt"
+	"io"
+	"strconv"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/spinner"
@@ -11,20 +13,25 @@ import (
 
 	"github.com/benji/cogito/internal/config"
 	shellctx "github.com/benji/cogito/internal/context"
+	"github.com/benji/cogito/internal/history"
 	"github.com/benji/cogito/internal/provider"
+	"github.com/benji/cogito/internal/redact"
+	"github.com/benji/cogito/internal/runner"
+	"github.com/benji/cogito/internal/session"
 	"github.com/benji/cogito/internal/ui"
 )
 
-var commands = []string{"/settings", "/help", "/clear"}
+var commands = []string{"/settings", "/help", "/clear", "/sessions", "/resume", "/rename", "/delete", "/run", "/insert", "/fix", "/profile", "/model", "/copy"}
 
 type Model struct {
 	state    AppState
 	config   config.Config
-	provider *provider.OpenAIProvider
+	provider provider.Provider
 
 	input    ui.InputModel
 	response ui.ResponseModel
 	settings ui.SettingsModel
+	picker   ui.ModelPickerModel
 	spinner  spinner.Model
 
 	width  int
@@ -39,6 +46,82 @@ type Model struct {
 
 	lastQuery string
 
+	// conversation holds the user and assistant turns sent with every
+	// query. The system prompt is rebuilt per request and not stored here.
+	conversation []provider.ChatMessage
+
+	// session is the on-disk record of the conversation, created lazily
+	// after the first completed reply.
+	session *session.Session
+
+	// Command execution (/run): the confirmed command, its streaming
+	// output, and output to prepend to the next query.
+	pendingRun     string
+	runOutput      string
+	runCh          <-chan string
+	runDoneCh      <-chan cmdDoneMsg
+	pendingContext string
+
+	// attachments names what pendingContext carries (piped stdin, files)
+	// for the status bar until the next query consumes it.
+	attachments []string
+
+	// tokens estimates the size of the query being typed; tokensFor is
+	// the input and pending context it was computed from. refTokens
+	// caches the estimates for @references.
+	tokens    int
+	tokensFor [2]string
+	refTokens map[string]int
+
+	// redactor masks secrets in everything sent to the provider; redacted
+	// counts what it replaced in the last query, for the status bar.
+	redactor *redact.Redactor
+	redacted int
+
+	// history holds past prompts; histIdx is the entry recalled with
+	// up/down (Len() when none is), histDraft what was typed before
+	// recalling, and histSearch the ctrl+r search in progress.
+	history    *history.History
+	histIdx    int
+	histDraft  string
+	histSearch *historySearch
+
+	// pagerSearching is set while a pager search is typed into
+	// pagerQuery; pagerBackward is set for ? rather than /.
+	pagerSearching bool
+	pagerQuery     string
+	pagerBackward  bool
+
+	// output is the program's terminal, for OSC 52 clipboard writes.
+	output io.Writer
+
+	// notice is a transient status bar message (e.g. after copying);
+	// noticeID tells its clearNoticeMsg from a newer notice's.
+	notice   string
+	noticeID int
+
+	// sessionModel is the model picked with /model for this session
+	// only; it overrides config.DefaultModel until settings or the
+	// profile change.
+	sessionModel string
+
+	// pickerForSettings is set when the model picker was opened from the
+	// settings screen, which it returns to.
+	pickerForSettings bool
+
+	// insertFile is set when launched from the shell widget; the picked
+	// command is written there instead of being run.
+	insertFile string
+
+	// startup is a query sent as soon as the program starts (cogito fix).
+	startup *startQueryMsg
+
+	// Commit mode (cogito commit): the prompt asking for a message, the
+	// message on offer, and git's output once committed.
+	commitPrompt string
+	commitMsg    string
+	commitOutput string
+
 	// topInline mode: top position without clear screen.
 	// Box is half-height and scroll is locked to keep render size fixed.
 	topInline bool
@@ -50,26 +133,51 @@ func NewModel(cfg config.Config) Model {
 	s.Style = ui.SpinnerStyle
 
 	ui.SetAccentColor(cfg.Theme.AccentColor)
-	p := provider.NewOpenAI(cfg.APIKey(), cfg.DefaultModel, cfg.BaseURL)
+	p, err := newProvider(cfg)
+	redactor, rerr := cfg.Redactor()
+	if err == nil {
+		err = rerr
+	}
+	response := ui.NewResponseModel()
+	response.SetMarkdown(!cfg.RawOutput)
+	hist := loadHistory(cfg)
 
 	return Model{
 		state:     StateInput,
 		config:    cfg,
 		provider:  p,
 		input:     ui.NewInputModel(),
-		response:  ui.NewResponseModel(),
+		response:  response,
 		spinner:   s,
+		err:       err,
+		hasError:  err != nil,
+		refTokens: make(map[string]int),
+		redactor:  redactor,
+		history:   hist,
+		histIdx:   hist.Len(),
 		topInline: !cfg.ClearScreen && cfg.Position == "top",
 	}
 }
 
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(
-		m.spinner.Tick,
-	)
+	cmds := []tea.Cmd{m.spinner.Tick}
+	if m.startup != nil {
+		startup := *m.startup
+		cmds = append(cmds, func() tea.Msg { return startup })
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	if nm, ok := next.(Model); ok {
+		nm.updateTokenEstimate()
+		return nm, cmd
+	}
+	return next, cmd
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -91,14 +199,31 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.handleKey(msg)
 
 	case streamChunkMsg:
+		if msg.stream != m.streamCh {
+			return m, nil
+		}
 		m.response.AppendContent(msg.chunk)
 		return m, listenForChunks(m.streamCh, m.streamErrCh)
 
 	case streamDoneMsg:
+		if msg.stream != m.streamCh {
+			return m, nil
+		}
 		m.streamCh = nil
 		m.streamErrCh = nil
 		m.cancelFunc = nil
 		m.response.Finalize()
+		m.conversation = append(m.conversation, provider.ChatMessage{
+			Role:    provider.RoleAssistant,
+			Content: m.response.Content(),
+		})
+		if m.commitPrompt != "" {
+			return m.showCommitMessage()
+		}
+		if err := m.saveSession(); err != nil {
+			m.err = fmt.Errorf("saving session: %w", err)
+			m.hasError = true
+		}
 		// Auto-enter pager if response overflows
 		if m.response.Overflows() {
 			m.state = StatePager
@@ -109,35 +234,110 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.state = StateInput
 		return m, m.input.Focus()
 
+	case cmdOutputMsg:
+		m.runOutput += msg.chunk
+		m.response.AppendContent(msg.chunk)
+		m.response.GotoBottom()
+		return m, listenForOutput(m.runCh, m.runDoneCh)
+
+	case cmdDoneMsg:
+		return m.finishRun(msg)
+
+	case commitDoneMsg:
+		return m.finishCommit(msg)
+
+	case modelsLoadedMsg:
+		return m.finishModelsLoad(msg)
+
+	case ui.ModelPickedMsg:
+		if m.pickerForSettings {
+			m.pickerForSettings = false
+			m.settings.SetModel(msg.Model)
+			m.state = StateSettings
+			return m, nil
+		}
+		return m.pickModel(msg)
+
+	case copiedMsg:
+		return m.finishCopy(msg)
+
+	case clearNoticeMsg:
+		if msg.id == m.noticeID {
+			m.notice = ""
+		}
+		return m, nil
+
+	case ui.ConnectionTestMsg:
+		return m.testConnection(msg)
+
+	case ui.SettingsPickModelMsg:
+		return m.pickModelForSettings(msg)
+
+	case editorDoneMsg:
+		if m.state == StateConfirmCommit {
+			return m.finishCommitEdit(msg)
+		}
+		return m.finishInputEdit(msg)
+
 	case streamErrMsg:
+		if msg.stream != m.streamCh {
+			return m, nil
+		}
 		m.state = StateInput
 		m.err = msg.err
 		m.hasError = true
+		m.dropPendingTurn()
 		m.streamCh = nil
 		m.streamErrCh = nil
 		m.cancelFunc = nil
+		if m.commitPrompt != "" {
+			m.state = StateConfirmCommit
+			return m, nil
+		}
 		return m, m.input.Focus()
 
 	case ui.SettingsSavedMsg:
 		if m.config.APIKeys == nil {
 			m.config.APIKeys = make(map[string]string)
 		}
-		m.config.APIKeys["openai"] = msg.APIKey
+		// Don't carry the previous provider's key over when only the
+		// provider was switched.
+		if msg.Provider == m.config.Provider || msg.APIKey != m.config.APIKey() {
+			m.config.APIKeys[msg.Provider] = msg.APIKey
+		}
+		m.config.Provider = msg.Provider
 		m.config.BaseURL = msg.BaseURL
 		m.config.DefaultModel = msg.DefaultModel
+		m.sessionModel = ""
 		m.config.CustomInstructions = msg.CustomInstructions
 		m.config.Context.IncludeCWD = msg.IncludeCWD
+		m.config.Context.IncludeShellHistory = msg.IncludeShellHistory
+		m.config.Context.ShellHistoryLines = msg.ShellHistoryLines
+		m.config.Context.IncludeGitBranch = msg.IncludeGitBranch
+		m.config.Context.IncludeGitStatus = msg.IncludeGitStatus
+		m.config.Context.IncludeGitLog = msg.IncludeGitLog
+		m.config.Context.IncludeGitDiff = msg.IncludeGitDiff
 		m.config.MaxResponseLines = msg.MaxResponseLines
 		m.config.ClearScreen = msg.ClearScreen
 		m.config.Position = msg.Position
 		m.config.Theme.AccentColor = msg.AccentColor
+		m.config.RawOutput = msg.RawOutput
 		ui.SetAccentColor(msg.AccentColor)
-		m.provider = provider.NewOpenAI(msg.APIKey, msg.DefaultModel, msg.BaseURL)
+		m.response.SetMarkdown(!msg.RawOutput)
+		m.provider, m.err = newProvider(m.config)
 		m.state = StateInput
-		_ = m.config.Save()
-		m.hasError = false
+		if err := m.config.Save(); err != nil && m.err == nil {
+			m.err = fmt.Errorf("saving settings: %w", err)
+		}
+		m.hasError = m.err != nil
 		return m, m.input.Focus()
 
+	case startQueryMsg:
+		return m.startQuery(msg.display, msg.content)
+
+	case systemReadyMsg:
+		return m.sendQuery(msg)
+
 	case spinner.TickMsg:
 		var cmd tea.Cmd
 		m.spinner, cmd = m.spinner.Update(msg)
@@ -150,6 +350,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch m.state {
 	case StateInput:
+		if m.histSearch != nil {
+			return m.updateHistorySearch(msg)
+		}
 		switch msg.String() {
 		case "esc":
 			return m, tea.Quit
@@ -157,6 +360,19 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 		case "ctrl+k":
 			return m, m.input.Focus()
+		case "ctrl+e":
+			m.hasError = false
+			return m, openEditor(m.input.Value())
+		case "ctrl+r":
+			return m.startHistorySearch()
+		case "up":
+			if m.input.Line() == 0 {
+				return m.historyPrev()
+			}
+		case "down":
+			if m.input.Line() == m.input.LineCount()-1 && m.histIdx < m.history.Len() {
+				return m.historyNext()
+			}
 		case "tab":
 			return m.handleTabComplete()
 		case "enter":
@@ -178,6 +394,16 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			if m.cancelFunc != nil {
 				m.cancelFunc()
 			}
+			// The stream may still report done or an error; with streamCh
+			// cleared those messages are dropped.
+			m.streamCh = nil
+			m.streamErrCh = nil
+			m.cancelFunc = nil
+			m.dropPendingTurn()
+			if m.commitPrompt != "" {
+				m.state = StateConfirmCommit
+				return m, nil
+			}
 			m.state = StateInput
 			return m, m.input.Focus()
 		case "ctrl+c":
@@ -186,8 +412,12 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case StatePager:
+		if m.pagerSearching {
+			return m.updatePagerSearch(msg)
+		}
 		switch msg.String() {
 		case "esc", "q":
+			m.response.ClearSearch()
 			m.state = StateInput
 			return m, m.input.Focus()
 		case "ctrl+c":
@@ -210,6 +440,68 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		case "G":
 			m.response.GotoBottom()
 			return m, nil
+		case "/", "?":
+			return m.startPagerSearch(msg.String() == "?")
+		case "n", "N":
+			m.response.NextMatch(msg.String() == "N")
+			return m, nil
+		case "y":
+			return m, copyText(m.output, m.response.Content(), "response")
+		case "Y":
+			return m.copyCommands()
+		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+			n, _ := strconv.Atoi(msg.String())
+			if m.insertFile != "" {
+				if cmds := m.response.Commands(); n <= len(cmds) {
+					return m.insertCommand(cmds[n-1])
+				}
+				return m, nil
+			}
+			return m.confirmRun(n)
+		}
+		return m, nil
+
+	case StateConfirmRun:
+		switch msg.String() {
+		case "y", "Y", "enter":
+			return m.startRun()
+		case "n", "N", "esc":
+			m.pendingRun = ""
+			m.state = StateInput
+			return m, m.input.Focus()
+		case "ctrl+c":
+			return m, tea.Quit
+		}
+		return m, nil
+
+	case StateConfirmCommit:
+		switch msg.String() {
+		case "y", "Y", "enter":
+			return m.startCommit()
+		case "e":
+			return m.editCommitMessage()
+		case "r":
+			return m.regenerateCommitMessage()
+		case "j", "down":
+			m.response.ScrollDown()
+			return m, nil
+		case "k", "up":
+			m.response.ScrollUp()
+			return m, nil
+		case "n", "N", "esc", "q", "ctrl+c":
+			return m, tea.Quit
+		}
+		return m, nil
+
+	case StateRunning:
+		switch msg.String() {
+		case "esc":
+			if m.cancelFunc != nil {
+				m.cancelFunc()
+			}
+			return m, nil
+		case "ctrl+c":
+			return m, tea.Quit
 		}
 		return m, nil
 
@@ -224,6 +516,23 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		var cmd tea.Cmd
 		m.settings, cmd = m.settings.Update(msg)
 		return m, cmd
+
+	case StateModelPicker:
+		switch msg.String() {
+		case "esc":
+			if m.pickerForSettings {
+				m.pickerForSettings = false
+				m.state = StateSettings
+				return m, nil
+			}
+			m.state = StateInput
+			return m, m.input.Focus()
+		case "ctrl+c":
+			return m, tea.Quit
+		}
+		var cmd tea.Cmd
+		m.picker, cmd = m.picker.Update(msg)
+		return m, cmd
 	}
 
 	return m, nil
@@ -231,6 +540,9 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 func (m Model) handleTabComplete() (tea.Model, tea.Cmd) {
 	val := m.input.Value()
+	if word := val[strings.LastIndexAny(val, " \t")+1:]; strings.HasPrefix(word, "@") {
+		return m.completePath(val)
+	}
 	if val == "" || val[0] != '/' {
 		return m, nil
 	}
@@ -275,17 +587,30 @@ func (m Model) handleSubmit() (tea.Model, tea.Cmd) {
 	if query == "" {
 		return m, nil
 	}
+	m.recordHistory(m.input.Value())
 
 	// Handle commands
 	switch {
 	case query == "/settings":
-		m.settings = ui.NewSettingsModel(
-			m.config.APIKey(), m.config.BaseURL, m.config.DefaultModel,
-			m.config.CustomInstructions,
-			m.config.Context.IncludeCWD,
-			m.config.ClearScreen, m.config.Position, m.config.Theme.AccentColor,
-			m.config.MaxResponseLines,
-		)
+		m.settings = ui.NewSettingsModel(provider.Names(), ui.SettingsValues{
+			Provider:            m.config.Provider,
+			APIKey:              m.config.APIKey(),
+			BaseURL:             m.config.BaseURL,
+			DefaultModel:        m.config.DefaultModel,
+			ClearScreen:         m.config.ClearScreen,
+			Position:            m.config.Position,
+			AccentColor:         m.config.Theme.AccentColor,
+			CustomInstructions:  m.config.CustomInstructions,
+			IncludeCWD:          m.config.Context.IncludeCWD,
+			IncludeShellHistory: m.config.Context.IncludeShellHistory,
+			ShellHistoryLines:   m.config.Context.ShellHistoryLines,
+			IncludeGitBranch:    m.config.Context.IncludeGitBranch,
+			IncludeGitStatus:    m.config.Context.IncludeGitStatus,
+			IncludeGitLog:       m.config.Context.IncludeGitLog,
+			IncludeGitDiff:      m.config.Context.IncludeGitDiff,
+			MaxResponseLines:    m.config.MaxResponseLines,
+			RawOutput:           m.config.RawOutput,
+		})
 		contentWidth := m.width - 6
 		if contentWidth > 0 {
 			m.settings.SetWidth(contentWidth)
@@ -297,20 +622,69 @@ func (m Model) handleSubmit() (tea.Model, tea.Cmd) {
 
 	case query == "/clear":
 		m.response.Clear()
+		m.conversation = nil
+		m.session = nil
+		m.pendingContext = ""
+		m.attachments = nil
+		m.lastQuery = ""
+		m.redacted = 0
 		m.input.SetValue("")
 		m.hasError = false
 		return m, nil
 
+	case query == "/sessions",
+		query == "/resume" || strings.HasPrefix(query, "/resume "),
+		query == "/rename" || strings.HasPrefix(query, "/rename "),
+		query == "/delete" || strings.HasPrefix(query, "/delete "):
+		return m.handleSessionCommand(query)
+
+	case query == "/run" || strings.HasPrefix(query, "/run "):
+		return m.handleRunCommand(query)
+
+	case query == "/insert" || strings.HasPrefix(query, "/insert "):
+		return m.handleInsertCommand(query)
+
+	case query == "/fix":
+		return m.handleFixCommand()
+
+	case query == "/profile" || strings.HasPrefix(query, "/profile "):
+		return m.handleProfileCommand(query)
+
+	case query == "/copy" || strings.HasPrefix(query, "/copy "):
+		return m.handleCopyCommand(query)
+
+	case query == "/model" || strings.HasPrefix(query, "/model "):
+		return m.handleModelCommand(query)
+
 	case query == "/help":
-		m.response.Clear()
-		m.response.AppendContent(helpText())
+		m.response.SetPlain(helpText())
+		m.input.SetValue("")
+		return m, nil
+	}
+
+	attachments, err := referenceAttachments(query)
+	if err != nil {
+		m.err = err
+		m.hasError = true
+		return m, nil
+	}
+	clear(m.refTokens)
+	return m.startQuery(query, attachments+query)
+}
+
+// startQuery sends content as the next user turn and starts streaming the
+// reply. display is what the header shows as the current query.
+func (m Model) startQuery(display, content string) (tea.Model, tea.Cmd) {
+	if m.provider == nil {
+		m.err = fmt.Errorf("provider %q is not available — run /settings", m.config.Provider)
+		m.hasError = true
 		m.input.SetValue("")
 		return m, nil
 	}
 
 	// Check for API key
-	if m.config.APIKey() == "" {
-		m.err = fmt.Errorf("no API key set — run /settings or set OPENAI_API_KEY")
+	if err := m.config.CheckAPIKey(); err != nil {
+		m.err = err
 		m.hasError = true
 		m.input.SetValue("")
 		return m, nil
@@ -320,7 +694,7 @@ func (m Model) handleSubmit() (tea.Model, tea.Cmd) {
 	m.response.Clear()
 	m.hasError = false
 	m.state = StateStreaming
-	m.lastQuery = query
+	m.lastQuery = display
 	m.input.SetValue("")
 	m.input.Blur()
 
@@ -332,16 +706,73 @@ func (m Model) handleSubmit() (tea.Model, tea.Cmd) {
 	m.streamCh = ch
 	m.streamErrCh = errCh
 
-	messages := []provider.ChatMessage{
-		{Role: provider.RoleSystem, Content: buildSystemMsg(m.config.Context.IncludeCWD, m.config.CustomInstructions)},
-		{Role: provider.RoleUser, Content: query},
+	if m.pendingContext != "" {
+		content = m.pendingContext + "\n" + content
+		m.pendingContext = ""
+		m.attachments = nil
+	}
+	// Secrets are masked before the turn is stored, so they never reach
+	// the provider or the saved session.
+	content, m.redacted = m.redactor.Redact(content)
+	m.conversation = append(m.conversation, provider.ChatMessage{Role: provider.RoleUser, Content: content})
+
+	ctxCfg, instructions, commit := m.config.Context, m.config.CustomInstructions, m.commitPrompt != ""
+	return m, func() tea.Msg {
+		if commit {
+			return systemReadyMsg{ctx: ctx, ch: ch, errCh: errCh, system: shellctx.BuildCommitSystemMessage(instructions)}
+		}
+		return systemReadyMsg{ctx: ctx, ch: ch, errCh: errCh, system: buildSystemMsg(ctxCfg, instructions)}
 	}
+}
+
+// sendQuery starts streaming once the system prompt is ready, unless the
+// query was cancelled in the meantime.
+func (m Model) sendQuery(msg systemReadyMsg) (tea.Model, tea.Cmd) {
+	if msg.ch != m.streamCh {
+		return m, nil
+	}
+	system, n := m.redactor.Redact(msg.system)
+	m.redacted += n
+
+	messages := make([]provider.ChatMessage, 0, len(m.conversation)+1)
+	messages = append(messages, provider.ChatMessage{
+		Role:    provider.RoleSystem,
+		Content: system,
+	})
+	messages = append(messages, m.conversation...)
 
+	p := m.provider
 	go func() {
-		errCh <- m.provider.StreamChat(ctx, messages, ch)
+		msg.errCh <- p.StreamChat(msg.ctx, messages, msg.ch)
 	}()
 
-	return m, listenForChunks(ch, errCh)
+	return m, listenForChunks(msg.ch, msg.errCh)
+}
+
+// Attach queues content (already formatted as a fenced block) to be sent
+// with the next query; label is shown in the status bar until then.
+func (m *Model) Attach(label, content string) {
+	m.pendingContext += content
+	m.attachments = append(m.attachments, label)
+}
+
+// dropPendingTurn removes a trailing user turn that never got an answer,
+// so the conversation keeps alternating user/assistant after a cancel or error.
+func (m *Model) dropPendingTurn() {
+	if n := len(m.conversation); n > 0 && m.conversation[n-1].Role == provider.RoleUser {
+		m.conversation = m.conversation[:n-1]
+	}
+}
+
+// turnCount returns the number of completed user/assistant exchanges.
+func (m Model) turnCount() int {
+	turns := 0
+	for _, msg := range m.conversation {
+		if msg.Role == provider.RoleAssistant {
+			turns++
+		}
+	}
+	return turns
 }
 
 func (m Model) updateSubmodels(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -351,6 +782,8 @@ func (m Model) updateSubmodels(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.input, cmd = m.input.Update(msg)
 	case StateSettings:
 		m.settings, cmd = m.settings.Update(msg)
+	case StateModelPicker:
+		m.picker, cmd = m.picker.Update(msg)
 	}
 	return m, cmd
 }
@@ -365,13 +798,19 @@ func (m Model) View() string {
 		contentWidth = 20
 	}
 
-	title := ui.RenderHeader(m.config.DefaultModel, m.lastQuery, m.width)
+	profile := m.config.ProfileName()
+	if profile == config.DefaultProfile {
+		profile = ""
+	}
+	title := ui.RenderHeader(m.activeModel(), profile, m.lastQuery, m.turnCount(), m.width)
 	topBorder := ui.RenderBorderTitle(title, m.width)
 
 	var content string
 	switch m.state {
 	case StateSettings:
 		content = m.settings.View()
+	case StateModelPicker:
+		content = m.picker.View()
 	default:
 		content = m.buildMainView(contentWidth)
 	}
@@ -424,7 +863,7 @@ func (m Model) buildMainView(width int) string {
 	// Response area
 	if m.response.Content() != "" || m.state == StateStreaming {
 		var responseView string
-		if m.response.Overflows() || m.state == StatePager || m.state == StateStreaming {
+		if m.response.Overflows() || m.state == StatePager || m.state == StateStreaming || m.state == StateRunning {
 			// Use fixed-height viewport for pager, streaming, and any overflowing content
 			responseView = m.response.PagerView()
 		} else {
@@ -447,16 +886,28 @@ func (m Model) buildMainView(width int) string {
 	}
 
 	// Input / Pager prompt
-	if m.state == StatePager {
-		pagerPrompt := ui.DimStyle.Render(":") + " " +
-			ui.DimStyle.Render(m.response.ScrollPercent())
-		parts = append(parts, pagerPrompt)
-	} else {
-		parts = append(parts, m.input.View())
+	switch m.state {
+	case StatePager:
+		parts = append(parts, m.pagerPrompt())
+	case StateConfirmRun:
+		parts = append(parts, ui.SelectedStyle.Render("Run ")+m.pendingRun+ui.SelectedStyle.Render(" ? [y/N]"))
+	case StateRunning:
+		parts = append(parts, m.spinner.View()+ui.DimStyle.Render(" running "+m.pendingRun))
+	case StateConfirmCommit:
+		parts = append(parts, ui.SelectedStyle.Render("Commit with this message? [y/N]"))
+	default:
+		if m.histSearch != nil {
+			parts = append(parts, m.historySearchView())
+		} else {
+			parts = append(parts, m.input.View())
+		}
 	}
 
 	// Status bar
 	status := m.statusBar()
+	if m.notice != "" {
+		status = m.notice + " • " + status
+	}
 	if status != "" {
 		parts = append(parts, ui.StatusBarStyle.Render(status))
 	}
@@ -467,14 +918,53 @@ func (m Model) buildMainView(width int) string {
 func (m Model) statusBar() string {
 	switch m.state {
 	case StateStreaming:
+		if notice := redact.Notice(m.redacted); notice != "" {
+			return notice + " • Streaming... (esc to cancel)"
+		}
 		return "Streaming... (esc to cancel)"
 	case StatePager:
-		return "space next • b back • j/k scroll • g/G top/bottom • esc/q exit"
+		if m.pagerSearching {
+			return "enter search • esc cancel"
+		}
+		hint := "space/b page • j/k scroll • g/G top/end • / ? search • n/N match • y/Y copy • q exit"
+		if n := len(m.response.Commands()); n > 0 {
+			action := "run"
+			if m.insertFile != "" {
+				action = "insert"
+			}
+			hint = fmt.Sprintf("1-%d %s • ", n, action) + hint
+		}
+		return hint
+	case StateConfirmRun:
+		return "y/enter run in " + runner.ShellName() + " • n/esc cancel"
+	case StateRunning:
+		return "Running... (esc to stop)"
+	case StateConfirmCommit:
+		return "y/enter commit • e edit • r regenerate • n/esc cancel"
 	default:
+		if m.histSearch != nil {
+			return "type to search • ctrl+r older • enter submit • esc cancel"
+		}
 		hint := "/help commands • /settings configure • esc quit"
+		if len(m.attachments) > 0 {
+			hint = "attached: " + strings.Join(m.attachments, ", ") + " • " + hint
+		}
+		if notice := redact.Notice(m.redacted); notice != "" {
+			hint = notice + " • " + hint
+		}
+		if q := m.input.Value(); q != "" && q[0] != '/' {
+			hint = fmt.Sprintf("~%d tokens • ", m.tokens) + hint
+		}
 		if m.response.Overflows() {
 			hint = ": scroll response • " + hint
 		}
+		if len(m.response.Commands()) > 0 {
+			if m.insertFile != "" {
+				hint = "/insert N to prompt • " + hint
+			} else {
+				hint = "/run N execute • " + hint
+			}
+		}
 		return hint
 	}
 }
@@ -490,16 +980,36 @@ func repeatStr(s string, count int) string {
 func helpText() string {
 	return `Commands:
   /settings   - Configure API key, model, and preferences
-  /clear      - Clear response
+  /clear      - Clear response and start a new conversation
+  /sessions   - List saved sessions
+  /resume     - Resume a session (latest if no name given)
+  /rename     - Rename the current session
+  /delete     - Delete a session (current if no name given)
+  /run N      - Run the Nth suggested command (1-9 in the pager)
+  /insert N   - Put the Nth command on your prompt (shell widget only)
+  /fix        - Suggest a fix for the last failed shell command
+  /profile    - List profiles, or switch with /profile <name>
+  /model      - Pick a model (or /model <name>); ctrl+s saves it as default
+  /copy [N]   - Copy the response, or its command [N], to the clipboard
   /help       - Show this help
 
 Shortcuts:
   Enter       - Submit query
-  Tab         - Autocomplete commands
+  Alt+Enter   - New line (also Ctrl+J, or Shift+Enter where the terminal sends it)
+  Ctrl+E      - Write the query in $EDITOR
+  Up/Down     - Recall earlier prompts (not ones starting with a space)
+  Ctrl+R      - Search earlier prompts
+  Tab         - Autocomplete commands and @paths
+  @path       - Attach a file's contents or a directory tree
   Ctrl+K      - Focus input
   Esc         - Quit (or cancel streaming)`
 }
 
-func buildSystemMsg(includeCWD bool, customInstructions string) string {
-	return shellctx.BuildSystemMessage(includeCWD, customInstructions)
+// newProvider builds the registered backend selected by cfg.Provider.
+func newProvider(cfg config.Config) (provider.Provider, error) {
+	return provider.New(cfg.Provider, cfg.ProviderConfig())
+}
+
+func buildSystemMsg(ctx config.ContextConfig, customInstructions string) string {
+	return shellctx.BuildSystemMessage(ctx, customInstructions)
 }
@@ -1,18 +1,50 @@
 package app
 
 import (
+	"context"
+
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-// Messages
+// Messages. Stream messages carry the channel of the stream they came
+// from, so ones from a cancelled stream can be told apart and dropped.
 type streamChunkMsg struct {
-	chunk string
+	stream <-chan string
+	chunk  string
 }
 
-type streamDoneMsg struct{}
+type streamDoneMsg struct {
+	stream <-chan string
+}
 
 type streamErrMsg struct {
-	err error
+	stream <-chan string
+	err    error
+}
+
+type cmdOutputMsg struct {
+	chunk string
+}
+
+type cmdDoneMsg struct {
+	exitCode int
+	err      error
+}
+
+// systemReadyMsg carries the system prompt built for the stream on ch.
+// Building it reads git and shell history, so it happens off the UI
+// goroutine.
+type systemReadyMsg struct {
+	ctx    context.Context
+	ch     chan string
+	errCh  chan error
+	system string
+}
+
+// startQueryMsg submits a query without it being typed in the input.
+type startQueryMsg struct {
+	display string
+	content string
 }
 
 type configSavedMsg struct{}
@@ -30,22 +62,33 @@ func listenForChunks(ch <-chan string, errCh <-chan error) tea.Cmd {
 				select {
 				case err := <-errCh:
 					if err != nil {
-						return streamErrMsg{err: err}
+						return streamErrMsg{stream: ch, err: err}
 					}
 				default:
 				}
-				return streamDoneMsg{}
+				return streamDoneMsg{stream: ch}
 			}
-			return streamChunkMsg{chunk: chunk}
+			return streamChunkMsg{stream: ch, chunk: chunk}
 		case err := <-errCh:
 			if err != nil {
-				return streamErrMsg{err: err}
+				return streamErrMsg{stream: ch, err: err}
 			}
 			// Drain remaining chunks
 			for chunk := range ch {
 				_ = chunk
 			}
-			return streamDoneMsg{}
+			return streamDoneMsg{stream: ch}
+		}
+	}
+}
+
+// listenForOutput reads one chunk of command output, or the final result
+// once the output channel is closed.
+func listenForOutput(ch <-chan string, doneCh <-chan cmdDoneMsg) tea.Cmd {
+	return func() tea.Msg {
+		if chunk, ok := <-ch; ok {
+			return cmdOutputMsg{chunk: chunk}
 		}
+		return <-doneCh
 	}
 }
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	shellctx "github.com/benji/cogito/internal/context"
+)
+
+// commitDoneMsg reports the result of git commit.
+type commitDoneMsg struct {
+	output string
+	err    error
+}
+
+// SetCommitPrompt enables commit mode: prompt is sent on startup and the
+// reply is offered as the message for git commit.
+func (m *Model) SetCommitPrompt(prompt string) {
+	m.commitPrompt = prompt
+	m.startup = &startQueryMsg{display: "commit message", content: prompt}
+}
+
+// CommitOutput returns what git commit printed, once it has succeeded.
+func (m Model) CommitOutput() string {
+	return m.commitOutput
+}
+
+// showCommitMessage takes the finished reply as the commit message and
+// asks for confirmation.
+func (m Model) showCommitMessage() (tea.Model, tea.Cmd) {
+	m.commitMsg = shellctx.CleanCommitMessage(m.response.Content())
+	m.response.SetPlain(m.commitMsg)
+	m.state = StateConfirmCommit
+	m.input.Blur()
+	return m, nil
+}
+
+// regenerateCommitMessage asks for a fresh message for the same diff.
+func (m Model) regenerateCommitMessage() (tea.Model, tea.Cmd) {
+	m.conversation = nil
+	m.commitMsg = ""
+	return m.startQuery("commit message", m.commitPrompt)
+}
+
+// editCommitMessage opens the message in $EDITOR.
+func (m Model) editCommitMessage() (tea.Model, tea.Cmd) {
+	m.hasError = false
+	return m, openEditor(m.commitMsg)
+}
+
+// finishCommitEdit takes the edited message back from $EDITOR.
+func (m Model) finishCommitEdit(msg editorDoneMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.err = fmt.Errorf("editor: %w", msg.err)
+		m.hasError = true
+		return m, nil
+	}
+	m.commitMsg = strings.TrimSpace(msg.text)
+	m.response.SetPlain(m.commitMsg)
+	return m, nil
+}
+
+// startCommit runs git commit with the confirmed message on stdin.
+func (m Model) startCommit() (tea.Model, tea.Cmd) {
+	if m.commitMsg == "" {
+		m.err = fmt.Errorf("empty commit message — press r to regenerate or e to edit")
+		m.hasError = true
+		return m, nil
+	}
+	m.hasError = false
+	m.state = StateRunning
+	m.pendingRun = "git commit"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelFunc = cancel
+	message := m.commitMsg
+
+	return m, func() tea.Msg {
+		cmd := exec.CommandContext(ctx, "git", "commit", "-F", "-")
+		cmd.Stdin = strings.NewReader(message + "\n")
+		var out bytes.Buffer
+		cmd.Stdout = &out
+		cmd.Stderr = &out
+		err := cmd.Run()
+		return commitDoneMsg{output: out.String(), err: err}
+	}
+}
+
+// finishCommit quits after a successful commit, or returns to the
+// confirmation prompt with git's complaint.
+func (m Model) finishCommit(msg commitDoneMsg) (tea.Model, tea.Cmd) {
+	m.cancelFunc = nil
+	m.pendingRun = ""
+	if msg.err != nil {
+		m.state = StateConfirmCommit
+		detail := strings.TrimSpace(msg.output)
+		if detail == "" {
+			detail = msg.err.Error()
+		}
+		m.err = fmt.Errorf("git commit failed: %s", detail)
+		m.hasError = true
+		return m, nil
+	}
+	m.commitOutput = msg.output
+	return m, tea.Quit
+}
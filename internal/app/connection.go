@@ -0,0 +1,63 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/benji/cogito/internal/provider"
+	"github.com/benji/cogito/internal/ui"
+)
+
+// connectionTestTimeout bounds the whole settings connection test.
+const connectionTestTimeout = 15 * time.Second
+
+// testConnection tries the API settings from the form without applying
+// them, reporting back to the settings screen.
+func (m Model) testConnection(msg ui.ConnectionTestMsg) (tea.Model, tea.Cmd) {
+	cfg := m.config
+	cfg.APIKeys = map[string]string{msg.Provider: msg.APIKey}
+	cfg.Provider = msg.Provider
+	cfg.BaseURL = msg.BaseURL
+	cfg.DefaultModel = msg.DefaultModel
+
+	return m, func() tea.Msg {
+		result := ui.ConnectionResultMsg{Model: cfg.DefaultModel}
+		p, err := provider.New(cfg.Provider, cfg.ProviderConfig())
+		if err != nil {
+			result.ListErr = err.Error()
+			return result
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), connectionTestTimeout)
+		defer cancel()
+		r := provider.Check(ctx, p, cfg.DefaultModel)
+		result.Models = r.Models
+		result.ListLatency = r.ListLatency
+		result.ChatLatency = r.ChatLatency
+		result.ModelMissing = !r.ModelListed
+		if r.ListErr != nil {
+			result.ListErr = provider.DescribeError(r.ListErr)
+		} else if len(r.Models) > 0 {
+			saveCachedModels(modelsCacheKey(cfg), r.Models)
+		}
+		if r.ChatErr != nil {
+			result.ChatErr = provider.DescribeError(r.ChatErr)
+		}
+		return result
+	}
+}
+
+// pickModelForSettings opens the model picker over the models listed by a
+// connection test; the choice fills the settings form.
+func (m Model) pickModelForSettings(msg ui.SettingsPickModelMsg) (tea.Model, tea.Cmd) {
+	m.picker = ui.NewModelPickerModel(msg.Current)
+	if w := m.width - 6; w > 0 {
+		m.picker.SetWidth(w)
+	}
+	m.picker.SetModels(msg.Models, "")
+	m.pickerForSettings = true
+	m.state = StateModelPicker
+	return m, nil
+}
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/benji/cogito/internal/clipboard"
+)
+
+// noticeDuration is how long a status bar notice stays up.
+const noticeDuration = 3 * time.Second
+
+// copiedMsg reports the result of putting what on the clipboard.
+type copiedMsg struct {
+	what string
+	err  error
+}
+
+// clearNoticeMsg takes down notice id unless a newer one replaced it.
+type clearNoticeMsg struct {
+	id int
+}
+
+// handleCopyCommand copies the response ("/copy") or the command labelled
+// [N] in it ("/copy N"), numbered as for /run.
+func (m Model) handleCopyCommand(query string) (tea.Model, tea.Cmd) {
+	m.input.SetValue("")
+	m.hasError = false
+
+	arg := strings.TrimSpace(strings.TrimPrefix(query, "/copy"))
+	if m.response.Content() == "" {
+		m.err = fmt.Errorf("nothing to copy yet")
+		m.hasError = true
+		return m, nil
+	}
+	if arg == "" {
+		return m, copyText(m.output, m.response.Content(), "response")
+	}
+
+	cmds := m.response.Commands()
+	n, err := strconv.Atoi(arg)
+	switch {
+	case len(cmds) == 0:
+		m.err = fmt.Errorf("no numbered commands in the current response")
+	case err != nil:
+		m.err = fmt.Errorf("usage: /copy [N] (1-%d)", len(cmds))
+	case n < 1 || n > len(cmds):
+		m.err = fmt.Errorf("no command [%d] — pick 1-%d", n, len(cmds))
+	default:
+		return m, copyText(m.output, cmds[n-1], fmt.Sprintf("command [%d]", n))
+	}
+	m.hasError = true
+	return m, nil
+}
+
+// copyCommands copies every numbered command in the response, separated
+// by blank lines (Y in the pager).
+func (m Model) copyCommands() (tea.Model, tea.Cmd) {
+	cmds := m.response.Commands()
+	switch len(cmds) {
+	case 0:
+		m.err = fmt.Errorf("no numbered commands in the current response")
+		m.hasError = true
+		return m, nil
+	case 1:
+		return m, copyText(m.output, cmds[0], "command [1]")
+	}
+	return m, copyText(m.output, strings.Join(cmds, "\n\n"), fmt.Sprintf("%d commands", len(cmds)))
+}
+
+// SetOutput gives the model the writer the program draws on, which the
+// OSC 52 sequence for copying is sent through.
+func (m *Model) SetOutput(w io.Writer) {
+	m.output = w
+}
+
+func copyText(w io.Writer, text, what string) tea.Cmd {
+	return func() tea.Msg {
+		return copiedMsg{what: what, err: clipboard.Copy(w, text)}
+	}
+}
+
+func (m Model) finishCopy(msg copiedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.err = fmt.Errorf("copy: %w", msg.err)
+		m.hasError = true
+		return m, nil
+	}
+	m.hasError = false
+	return m, m.showNotice("Copied " + msg.what + " to clipboard")
+}
+
+// showNotice puts text in the status bar for noticeDuration.
+func (m *Model) showNotice(text string) tea.Cmd {
+	m.noticeID++
+	m.notice = text
+	id := m.noticeID
+	return tea.Tick(noticeDuration, func(time.Time) tea.Msg { return clearNoticeMsg{id: id} })
+}
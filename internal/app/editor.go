@@ -0,0 +1,67 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// editorDoneMsg carries the text saved in $EDITOR, or the error that
+// prevented editing it.
+type editorDoneMsg struct {
+	text string
+	err  error
+}
+
+// openEditor suspends the UI and opens text in $VISUAL or $EDITOR (vi if
+// neither is set). The edited text comes back as an editorDoneMsg.
+func openEditor(text string) tea.Cmd {
+	f, err := os.CreateTemp("", "cogito-*.txt")
+	if err != nil {
+		return func() tea.Msg { return editorDoneMsg{err: err} }
+	}
+	path := f.Name()
+	_, err = f.WriteString(text)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return func() tea.Msg { return editorDoneMsg{err: err} }
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	args := strings.Fields(editor)
+	if len(args) == 0 {
+		args = []string{"vi"}
+	}
+	cmd := exec.Command(args[0], append(args[1:], path)...)
+	// The program's output is wrapped; the editor needs the terminal.
+	cmd.Stdout = os.Stdout
+
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		defer os.Remove(path)
+		if err != nil {
+			return editorDoneMsg{err: err}
+		}
+		data, err := os.ReadFile(path)
+		return editorDoneMsg{text: string(data), err: err}
+	})
+}
+
+// finishInputEdit loads the text written in $EDITOR back into the input.
+func (m Model) finishInputEdit(msg editorDoneMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.err = fmt.Errorf("editor: %w", msg.err)
+		m.hasError = true
+		return m, m.input.Focus()
+	}
+	m.input.SetValue(strings.TrimRight(msg.text, "\n"))
+	return m, m.input.Focus()
+}
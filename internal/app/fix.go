@@ -0,0 +1,33 @@
+package app
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	shellctx "github.com/benji/cogito/internal/context"
+)
+
+// SetFixCommand queues a fix request for fc to be sent on startup.
+func (m *Model) SetFixCommand(fc shellctx.FailedCommand) {
+	m.startup = &startQueryMsg{
+		display: "fix: " + fc.Command,
+		content: shellctx.BuildFixPrompt(fc),
+	}
+}
+
+// handleFixCommand sends a fix request for the last command recorded by
+// the shell hook, unless that command succeeded.
+func (m Model) handleFixCommand() (tea.Model, tea.Cmd) {
+	m.input.SetValue("")
+	fc, ok := shellctx.LastCommandFromEnv()
+	if !ok {
+		m.err = fmt.Errorf("no command recorded — load the shell hook with cogito shell-init")
+		m.hasError = true
+		return m, nil
+	}
+	if !fc.Failed() {
+		return m, m.showNotice("last command succeeded — nothing to fix")
+	}
+	return m.startQuery("fix: "+fc.Command, shellctx.BuildFixPrompt(fc))
+}
@@ -0,0 +1,153 @@
+package app
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/benji/cogito/internal/config"
+	"github.com/benji/cogito/internal/history"
+	"github.com/benji/cogito/internal/redact"
+	"github.com/benji/cogito/internal/ui"
+)
+
+// historySearch is the state of a ctrl+r reverse search.
+type historySearch struct {
+	query string
+	// match indexes the history entry shown, -1 when nothing matches.
+	match int
+	// draft is the input from before the search, restored on cancel.
+	draft string
+}
+
+// loadHistory opens the prompt history, or returns nil when it is
+// disabled or unreadable. Secrets are checked with the redaction rules
+// even when redaction itself is turned off.
+func loadHistory(cfg config.Config) *history.History {
+	if !cfg.History.Enabled {
+		return nil
+	}
+	path, err := config.HistoryPath()
+	if err != nil {
+		return nil
+	}
+	r, err := redact.New(cfg.Redaction.Rules)
+	if err != nil {
+		r, _ = redact.New(nil)
+	}
+	h, _ := history.Load(path, cfg.History.MaxEntries, r)
+	return h
+}
+
+// recordHistory adds a submitted prompt and resets recall to the newest
+// entry.
+func (m *Model) recordHistory(prompt string) {
+	_ = m.history.Add(prompt)
+	m.histIdx = m.history.Len()
+	m.histDraft = ""
+}
+
+// historyPrev recalls the next older prompt, keeping what was typed so
+// historyNext can bring it back.
+func (m Model) historyPrev() (tea.Model, tea.Cmd) {
+	if m.histIdx <= 0 {
+		return m, nil
+	}
+	if m.histIdx >= m.history.Len() {
+		m.histDraft = m.input.Value()
+	}
+	m.histIdx = min(m.histIdx, m.history.Len()) - 1
+	m.input.SetValue(m.history.Entries()[m.histIdx])
+	return m, nil
+}
+
+// historyNext recalls the next newer prompt, or the draft after the newest.
+func (m Model) historyNext() (tea.Model, tea.Cmd) {
+	m.histIdx++
+	if m.histIdx >= m.history.Len() {
+		m.histIdx = m.history.Len()
+		m.input.SetValue(m.histDraft)
+		return m, nil
+	}
+	m.input.SetValue(m.history.Entries()[m.histIdx])
+	return m, nil
+}
+
+func (m Model) startHistorySearch() (tea.Model, tea.Cmd) {
+	m.histSearch = &historySearch{match: -1, draft: m.input.Value()}
+	return m, nil
+}
+
+// updateHistorySearch handles keys during ctrl+r search like readline:
+// typing narrows the search, ctrl+r finds the next older match, enter
+// submits the match, esc or ctrl+g restores the draft, and any other key
+// keeps the match and goes back to editing.
+func (m Model) updateHistorySearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	s := *m.histSearch
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "ctrl+g":
+		m.input.SetValue(s.draft)
+		m.histSearch = nil
+		return m, nil
+	case "ctrl+r":
+		if s.query != "" {
+			before := m.history.Len()
+			if s.match >= 0 {
+				before = s.match
+			}
+			if i := m.history.Search(s.query, before); i >= 0 {
+				s.match = i
+			}
+		}
+		m.histSearch = &s
+		return m, nil
+	case "backspace":
+		if r := []rune(s.query); len(r) > 0 {
+			s.query = string(r[:len(r)-1])
+		}
+		s.match = -1
+		if s.query != "" {
+			s.match = m.history.Search(s.query, m.history.Len())
+		}
+		m.histSearch = &s
+		return m, nil
+	}
+
+	if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+		s.query += string(msg.Runes)
+		before := m.history.Len()
+		if s.match >= 0 {
+			before = s.match + 1
+		}
+		s.match = m.history.Search(s.query, before)
+		m.histSearch = &s
+		return m, nil
+	}
+
+	m.histSearch = nil
+	if s.match >= 0 {
+		m.input.SetValue(m.history.Entries()[s.match])
+		m.histIdx = s.match
+		m.histDraft = s.draft
+	}
+	if msg.String() == "enter" {
+		return m.handleSubmit()
+	}
+	return m.handleKey(msg)
+}
+
+// historySearchView replaces the input while searching.
+func (m Model) historySearchView() string {
+	s := m.histSearch
+	label := "(reverse-i-search)`"
+	match := ""
+	if s.match >= 0 {
+		match = m.history.Entries()[s.match]
+	} else if s.query != "" {
+		label = "(failing reverse-i-search)`"
+	}
+	match = strings.ReplaceAll(match, "\n", "\n  ")
+	return ui.DimStyle.Render(label) + s.query + ui.DimStyle.Render("': ") + match
+}
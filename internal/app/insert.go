@@ -0,0 +1,53 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// SetInsertFile enables shell-widget mode: picking a command writes it to
+// path and quits, so the widget can load it into the prompt line.
+func (m *Model) SetInsertFile(path string) {
+	m.insertFile = path
+}
+
+// handleInsertCommand parses "/insert N".
+func (m Model) handleInsertCommand(query string) (tea.Model, tea.Cmd) {
+	m.input.SetValue("")
+	m.hasError = false
+
+	cmds := m.response.Commands()
+	arg := strings.TrimSpace(strings.TrimPrefix(query, "/insert"))
+	if arg == "" && len(cmds) == 1 {
+		arg = "1"
+	}
+	n, err := strconv.Atoi(arg)
+	switch {
+	case m.insertFile == "":
+		m.err = fmt.Errorf("/insert needs the shell widget — see cogito shell-init")
+	case len(cmds) == 0:
+		m.err = fmt.Errorf("no commands in the current response")
+	case err != nil:
+		m.err = fmt.Errorf("usage: /insert N (1-%d)", len(cmds))
+	case n < 1 || n > len(cmds):
+		m.err = fmt.Errorf("no command %d — pick 1-%d", n, len(cmds))
+	default:
+		return m.insertCommand(cmds[n-1])
+	}
+	m.hasError = true
+	return m, nil
+}
+
+// insertCommand hands command to the shell widget and quits.
+func (m Model) insertCommand(command string) (tea.Model, tea.Cmd) {
+	if err := os.WriteFile(m.insertFile, []byte(command), 0o600); err != nil {
+		m.err = err
+		m.hasError = true
+		return m, nil
+	}
+	return m, tea.Quit
+}
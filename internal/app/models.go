@@ -0,0 +1,160 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/benji/cogito/internal/config"
+	"github.com/benji/cogito/internal/ui"
+)
+
+const (
+	// modelsCacheTTL is how long a fetched model list is reused.
+	modelsCacheTTL = 6 * time.Hour
+	// listModelsTimeout bounds the ListModels request.
+	listModelsTimeout = 10 * time.Second
+)
+
+// modelsLoadedMsg carries the result of listing the provider's models.
+type modelsLoadedMsg struct {
+	models []string
+	err    error
+}
+
+type modelsCacheEntry struct {
+	Models    []string  `json:"models"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// handleModelCommand opens the model picker ("/model") or switches
+// straight to a named model ("/model <name>").
+func (m Model) handleModelCommand(query string) (tea.Model, tea.Cmd) {
+	name := strings.TrimSpace(strings.TrimPrefix(query, "/model"))
+	m.input.SetValue("")
+	m.hasError = false
+
+	if name != "" {
+		return m.pickModel(ui.ModelPickedMsg{Model: name})
+	}
+
+	m.picker = ui.NewModelPickerModel(m.activeModel())
+	if w := m.width - 6; w > 0 {
+		m.picker.SetWidth(w)
+	}
+	m.state = StateModelPicker
+	m.input.Blur()
+
+	key := modelsCacheKey(m.config)
+	if models, ok := cachedModels(key); ok {
+		m.picker.SetModels(models, "")
+		return m, nil
+	}
+	if m.provider == nil {
+		m.picker.SetModels(m.config.AvailableModels, "provider unavailable — showing available_models from config")
+		return m, nil
+	}
+	p := m.provider
+	return m, func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), listModelsTimeout)
+		defer cancel()
+		models, err := p.ListModels(ctx)
+		if err == nil && len(models) > 0 {
+			saveCachedModels(key, models)
+		}
+		return modelsLoadedMsg{models: models, err: err}
+	}
+}
+
+// finishModelsLoad fills the picker, falling back to AvailableModels when
+// the provider couldn't list any.
+func (m Model) finishModelsLoad(msg modelsLoadedMsg) (tea.Model, tea.Cmd) {
+	if m.state != StateModelPicker {
+		return m, nil
+	}
+	switch {
+	case msg.err != nil:
+		m.picker.SetModels(m.config.AvailableModels, fmt.Sprintf("couldn't list models (%v) — showing available_models from config", msg.err))
+	case len(msg.models) == 0:
+		m.picker.SetModels(m.config.AvailableModels, "the provider listed no models — showing available_models from config")
+	default:
+		m.picker.SetModels(msg.models, "")
+	}
+	return m, nil
+}
+
+// pickModel switches the provider to the chosen model for this session,
+// saving it as the default when asked. A session-only choice is kept out
+// of config.DefaultModel so a later settings save doesn't persist it.
+func (m Model) pickModel(msg ui.ModelPickedMsg) (tea.Model, tea.Cmd) {
+	m.state = StateInput
+	if m.provider != nil {
+		m.provider.SetModel(msg.Model)
+	}
+	status := "Using " + msg.Model + " for this session"
+	if msg.Persist {
+		m.config.DefaultModel = msg.Model
+		m.sessionModel = ""
+		if err := m.config.Save(); err != nil {
+			m.err = fmt.Errorf("saving default model: %w", err)
+			m.hasError = true
+		} else {
+			status = "Using " + msg.Model + " (saved as default)"
+		}
+	} else {
+		m.sessionModel = msg.Model
+	}
+	m.response.SetPlain(status)
+	return m, m.input.Focus()
+}
+
+// activeModel is the model queries go to: the session's /model choice,
+// or the configured default.
+func (m Model) activeModel() string {
+	if m.sessionModel != "" {
+		return m.sessionModel
+	}
+	return m.config.DefaultModel
+}
+
+func modelsCacheKey(cfg config.Config) string {
+	return cfg.Provider + "|" + cfg.BaseURL
+}
+
+func loadModelsCache() map[string]modelsCacheEntry {
+	cache := map[string]modelsCacheEntry{}
+	path, err := config.ModelsCachePath()
+	if err != nil {
+		return cache
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(data, &cache)
+	}
+	return cache
+}
+
+// cachedModels returns a model list fetched within modelsCacheTTL.
+func cachedModels(key string) ([]string, bool) {
+	e, ok := loadModelsCache()[key]
+	if !ok || len(e.Models) == 0 || time.Since(e.FetchedAt) > modelsCacheTTL {
+		return nil, false
+	}
+	return e.Models, true
+}
+
+func saveCachedModels(key string, models []string) {
+	path, err := config.ModelsCachePath()
+	if err != nil {
+		return
+	}
+	cache := loadModelsCache()
+	cache[key] = modelsCacheEntry{Models: models, FetchedAt: time.Now()}
+	if data, err := json.MarshalIndent(cache, "", "  "); err == nil {
+		_ = os.WriteFile(path, data, 0o600)
+	}
+}
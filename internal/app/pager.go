@@ -0,0 +1,68 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/benji/cogito/internal/ui"
+)
+
+// startPagerSearch begins typing a pager search, forward for / and
+// backward for ?.
+func (m Model) startPagerSearch(backward bool) (tea.Model, tea.Cmd) {
+	m.pagerSearching = true
+	m.pagerBackward = backward
+	m.pagerQuery = ""
+	return m, nil
+}
+
+// updatePagerSearch edits the search being typed. Enter runs it (an empty
+// query repeats the last search in the new direction); esc, or backspace
+// on an empty query, cancels.
+func (m Model) updatePagerSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.pagerSearching = false
+		return m, nil
+	case "enter":
+		m.pagerSearching = false
+		query := m.pagerQuery
+		if query == "" {
+			query = m.response.SearchQuery()
+		}
+		if query != "" {
+			m.response.Search(query, m.pagerBackward)
+		}
+		return m, nil
+	case "backspace":
+		if m.pagerQuery == "" {
+			m.pagerSearching = false
+			return m, nil
+		}
+		r := []rune(m.pagerQuery)
+		m.pagerQuery = string(r[:len(r)-1])
+		return m, nil
+	}
+	if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+		m.pagerQuery += string(msg.Runes)
+	}
+	return m, nil
+}
+
+// pagerPrompt is the line under the pager: the search being typed, or the
+// scroll position and match count.
+func (m Model) pagerPrompt() string {
+	if m.pagerSearching {
+		prefix := "/"
+		if m.pagerBackward {
+			prefix = "?"
+		}
+		return ui.InputPromptStyle.Render(prefix) + m.pagerQuery + ui.DimStyle.Render("█")
+	}
+	prompt := ui.DimStyle.Render(":") + " " + ui.DimStyle.Render(m.response.ScrollPercent())
+	if status := m.response.SearchStatus(); status != "" {
+		prompt += "  " + ui.DimStyle.Render(status)
+	}
+	return prompt
+}
@@ -0,0 +1,69 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/benji/cogito/internal/config"
+	"github.com/benji/cogito/internal/ui"
+)
+
+// handleProfileCommand lists profiles ("/profile") or switches to one
+// ("/profile <name>"), rebuilding the provider in place. The conversation
+// carries over to the new profile.
+func (m Model) handleProfileCommand(query string) (tea.Model, tea.Cmd) {
+	name := strings.TrimSpace(strings.TrimPrefix(query, "/profile"))
+	m.input.SetValue("")
+	m.hasError = false
+
+	if name == "" {
+		m.response.SetPlain(m.profileList())
+		return m, nil
+	}
+
+	cfg, err := m.config.UseProfile(name)
+	if err != nil {
+		m.err = err
+		m.hasError = true
+		return m, nil
+	}
+	m.config = cfg
+	m.sessionModel = ""
+	ui.SetAccentColor(cfg.Theme.AccentColor)
+	m.provider, m.err = newProvider(cfg)
+	m.hasError = m.err != nil
+	m.response.SetPlain(fmt.Sprintf("Switched to profile %s (%s, %s)", cfg.ProfileName(), cfg.Provider, cfg.DefaultModel))
+	return m, nil
+}
+
+func (m Model) profileList() string {
+	var b strings.Builder
+	b.WriteString("Profiles (/profile <name>):\n")
+	for _, name := range m.config.ProfileNames() {
+		marker := "  "
+		if name == m.config.ProfileName() {
+			marker = "* "
+		}
+		providerName, model := m.config.Provider, m.config.DefaultModel
+		if p, ok := m.config.Profiles[name]; ok {
+			providerName, model = orInherit(p.Provider), orInherit(p.Model)
+		} else if name == config.DefaultProfile && m.config.ProfileName() != config.DefaultProfile {
+			base, _ := m.config.UseProfile(config.DefaultProfile)
+			providerName, model = base.Provider, base.DefaultModel
+		}
+		fmt.Fprintf(&b, "%s%-16s %-10s %s\n", marker, name, providerName, model)
+	}
+	if len(m.config.Profiles) == 0 {
+		b.WriteString("\nAdd profiles under \"profiles\" in config.json.")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func orInherit(s string) string {
+	if s == "" {
+		return "(default)"
+	}
+	return s
+}
@@ -0,0 +1,100 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	shellctx "github.com/benji/cogito/internal/context"
+)
+
+// referenceAttachments resolves the @file/@dir tokens in query into
+// fenced attachments.
+func referenceAttachments(query string) (string, error) {
+	var b strings.Builder
+	for _, path := range shellctx.ParseReferences(query) {
+		attachment, err := shellctx.ResolveReference(path)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(attachment + "\n")
+	}
+	return b.String(), nil
+}
+
+// updateTokenEstimate recomputes the size of the query being typed,
+// including @references and pending attachments, when either changed.
+// It runs after every Update so rendering needs no file system access.
+func (m *Model) updateTokenEstimate() {
+	query := m.input.Value()
+	key := [2]string{query, m.pendingContext}
+	if key == m.tokensFor {
+		return
+	}
+	m.tokensFor = key
+	m.tokens = 0
+	if query == "" || query[0] == '/' {
+		return
+	}
+	m.tokens = shellctx.EstimateTokens(query) + shellctx.EstimateTokens(m.pendingContext)
+	for _, path := range shellctx.ParseReferences(query) {
+		n, ok := m.refTokens[path]
+		if !ok {
+			n = shellctx.EstimateReferenceTokens(path)
+			m.refTokens[path] = n
+		}
+		m.tokens += n
+	}
+}
+
+// completePath completes the @path word at the end of the input.
+func (m Model) completePath(val string) (tea.Model, tea.Cmd) {
+	start := strings.LastIndexAny(val, " \t") + 1
+	word := val[start:]
+	prefix := strings.TrimPrefix(word, "@")
+
+	dir, base := filepath.Split(prefix)
+	listDir := dir
+	if listDir == "" {
+		listDir = "."
+	}
+	entries, err := os.ReadDir(listDir)
+	if err != nil {
+		return m, nil
+	}
+
+	var matches []string
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".") {
+			continue
+		}
+		if !strings.HasPrefix(name, base) {
+			continue
+		}
+		if e.IsDir() {
+			name += "/"
+		}
+		matches = append(matches, dir+name)
+	}
+	sort.Strings(matches)
+
+	switch {
+	case len(matches) == 1:
+		m.input.SetValue(val[:start] + "@" + matches[0])
+		m.input.SetSuggestion("")
+	case len(matches) > 1:
+		common := matches[0]
+		for _, match := range matches[1:] {
+			common = commonPrefix(common, match)
+		}
+		if len(common) > len(prefix) {
+			m.input.SetValue(val[:start] + "@" + common)
+		}
+		m.input.SetSuggestion(matches[0][len(common):])
+	}
+	return m, nil
+}
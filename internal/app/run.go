@@ -0,0 +1,120 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	shellctx "github.com/benji/cogito/internal/context"
+	"github.com/benji/cogito/internal/runner"
+)
+
+// maxCommandContext caps how much command output is attached to the
+// next query when AppendCommandOutput is enabled.
+const maxCommandContext = 8 * 1024
+
+// handleRunCommand parses "/run N" and asks for confirmation.
+func (m Model) handleRunCommand(query string) (tea.Model, tea.Cmd) {
+	m.input.SetValue("")
+	m.hasError = false
+
+	cmds := m.response.Commands()
+	arg := strings.TrimSpace(strings.TrimPrefix(query, "/run"))
+	if arg == "" && len(cmds) == 1 {
+		arg = "1"
+	}
+	n, err := strconv.Atoi(arg)
+	switch {
+	case len(cmds) == 0:
+		m.err = fmt.Errorf("no commands in the current response")
+	case err != nil:
+		m.err = fmt.Errorf("usage: /run N (1-%d)", len(cmds))
+	case n < 1 || n > len(cmds):
+		m.err = fmt.Errorf("no command %d — pick 1-%d", n, len(cmds))
+	default:
+		return m.confirmRun(n)
+	}
+	m.hasError = true
+	return m, nil
+}
+
+// confirmRun shows command n and waits for y/n.
+func (m Model) confirmRun(n int) (tea.Model, tea.Cmd) {
+	cmds := m.response.Commands()
+	if n < 1 || n > len(cmds) {
+		return m, nil
+	}
+	m.pendingRun = cmds[n-1]
+	m.state = StateConfirmRun
+	m.input.Blur()
+	return m, nil
+}
+
+// startRun executes the confirmed command and streams its output into
+// the response box.
+func (m Model) startRun() (tea.Model, tea.Cmd) {
+	command := m.pendingRun
+	m.state = StateRunning
+	m.runOutput = ""
+
+	cwd, _ := os.Getwd()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelFunc = cancel
+
+	ch := make(chan string, 64)
+	doneCh := make(chan cmdDoneMsg, 1)
+	m.runCh = ch
+	m.runDoneCh = doneCh
+
+	m.response.AppendContent("\n\n~~~~\n$ " + command + "\n")
+	m.response.GotoBottom()
+
+	go func() {
+		code, err := runner.Run(ctx, command, cwd, ch)
+		doneCh <- cmdDoneMsg{exitCode: code, err: err}
+	}()
+
+	return m, listenForOutput(ch, doneCh)
+}
+
+// finishRun closes the output block, records the result and returns to
+// the input (or pager, when the output overflows).
+func (m Model) finishRun(msg cmdDoneMsg) (tea.Model, tea.Cmd) {
+	m.runCh = nil
+	m.runDoneCh = nil
+	m.cancelFunc = nil
+
+	if m.runOutput != "" && !strings.HasSuffix(m.runOutput, "\n") {
+		m.response.AppendContent("\n")
+	}
+	status := fmt.Sprintf("exit status %d", msg.exitCode)
+	if msg.err != nil {
+		status = "failed: " + msg.err.Error()
+	}
+	m.response.AppendContent("~~~~\n" + status)
+	m.response.Finalize()
+
+	if m.config.AppendCommandOutput {
+		output := m.runOutput
+		truncated := len(output) > maxCommandContext
+		if truncated {
+			output = output[len(output)-maxCommandContext:]
+		}
+		label := fmt.Sprintf("I ran `%s` (%s). Output", m.pendingRun, status)
+		m.Attach("command output", shellctx.FormatAttachment(label, output, truncated))
+	}
+	m.pendingRun = ""
+	m.runOutput = ""
+
+	if m.response.Overflows() {
+		m.state = StatePager
+		m.response.GotoBottom()
+		return m, nil
+	}
+	m.state = StateInput
+	return m, m.input.Focus()
+}
@@ -0,0 +1,144 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/benji/cogito/internal/provider"
+	"github.com/benji/cogito/internal/session"
+)
+
+// saveSession persists the current conversation, creating a new
+// timestamp-named session the first time a reply completes.
+func (m *Model) saveSession() error {
+	if len(m.conversation) == 0 {
+		return nil
+	}
+	if m.session == nil {
+		cwd, _ := os.Getwd()
+		m.session = &session.Session{
+			Name: session.NewName(time.Now()),
+			CWD:  cwd,
+		}
+	}
+	m.session.Model = m.activeModel()
+	m.session.BaseURL = m.config.BaseURL
+	m.session.Messages = m.conversation
+	return session.Save(m.session)
+}
+
+// ResumeSession loads a saved session into the model. An empty name
+// resumes the most recently updated session.
+func (m *Model) ResumeSession(name string) error {
+	var (
+		s   session.Session
+		err error
+	)
+	if name == "" {
+		s, err = session.Latest()
+	} else {
+		s, err = session.Load(name)
+	}
+	if err != nil {
+		return err
+	}
+
+	m.session = &s
+	m.conversation = append([]provider.ChatMessage(nil), s.Messages...)
+	m.lastQuery = ""
+	m.response.Clear()
+	m.hasError = false
+	for i := len(s.Messages) - 1; i >= 0; i-- {
+		if s.Messages[i].Role == provider.RoleUser && m.lastQuery == "" {
+			m.lastQuery = s.Messages[i].Content
+		}
+		if s.Messages[i].Role == provider.RoleAssistant && m.response.Content() == "" {
+			m.response.AppendContent(s.Messages[i].Content)
+		}
+	}
+	// Number the reply's commands for /run, /insert and /copy.
+	m.response.Finalize()
+	return nil
+}
+
+func (m Model) handleSessionCommand(query string) (tea.Model, tea.Cmd) {
+	name, arg, _ := strings.Cut(query, " ")
+	arg = strings.TrimSpace(arg)
+	m.input.SetValue("")
+	m.hasError = false
+
+	var err error
+	switch name {
+	case "/sessions":
+		var list string
+		list, err = m.sessionList()
+		if err == nil {
+			m.response.SetPlain(list)
+		}
+
+	case "/resume":
+		err = m.ResumeSession(arg)
+
+	case "/rename":
+		switch {
+		case arg == "":
+			err = fmt.Errorf("usage: /rename <new-name>")
+		case m.session == nil:
+			err = fmt.Errorf("no active session to rename — ask something first")
+		default:
+			if err = session.Rename(m.session.Name, arg); err == nil {
+				m.session.Name = arg
+				m.response.SetPlain("Session renamed to " + arg)
+			}
+		}
+
+	case "/delete":
+		target := arg
+		if target == "" && m.session != nil {
+			target = m.session.Name
+		}
+		if target == "" {
+			err = fmt.Errorf("usage: /delete <name>")
+			break
+		}
+		if err = session.Delete(target); err == nil {
+			if m.session != nil && m.session.Name == target {
+				m.session = nil
+			}
+			m.response.SetPlain("Deleted session " + target)
+		}
+	}
+
+	if err != nil {
+		m.err = err
+		m.hasError = true
+	}
+	return m, nil
+}
+
+func (m Model) sessionList() (string, error) {
+	sessions, err := session.List()
+	if err != nil {
+		return "", err
+	}
+	if len(sessions) == 0 {
+		return "No saved sessions.", nil
+	}
+
+	var b strings.Builder
+	b.WriteString("Sessions (/resume <name>):\n")
+	for _, s := range sessions {
+		marker := "  "
+		if m.session != nil && m.session.Name == s.Name {
+			marker = "* "
+		}
+		fmt.Fprintf(&b, "%s%-20s %3d turns  %s  %s  %s\n",
+			marker, s.Name, s.Turns(), s.Model,
+			s.UpdatedAt.Format("Jan 02 15:04"), s.CWD)
+	}
+	return strings.TrimRight(b.String(), "\n"), nil
+}
@@ -3,8 +3,12 @@ package app
 type AppState int
 
 const (
-	StateInput    AppState = iota
+	StateInput AppState = iota
 	StateStreaming
 	StateSettings
 	StatePager
+	StateConfirmRun
+	StateRunning
+	StateConfirmCommit
+	StateModelPicker
 )
@@ -0,0 +1,101 @@
+// Package clipboard puts text on the user's clipboard. It emits the OSC 52
+// escape sequence, which the terminal handles even over SSH and inside
+// tmux, and also hands the text to wl-copy or xclip on a local desktop
+// whose terminal may not support OSC 52.
+package clipboard
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"golang.org/x/term"
+)
+
+// Output wraps the terminal a Bubble Tea program draws on. Its writes are
+// serialized, so Copy can send the OSC 52 sequence through it without
+// landing in the middle of a frame. It keeps the file's Fd, so the
+// program still sees a terminal.
+type Output struct {
+	f  *os.File
+	mu sync.Mutex
+}
+
+// NewOutput wraps f, usually os.Stdout.
+func NewOutput(f *os.File) *Output {
+	return &Output{f: f}
+}
+
+func (o *Output) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.f.Write(p)
+}
+
+func (o *Output) Read(p []byte) (int, error) { return o.f.Read(p) }
+func (o *Output) Close() error               { return o.f.Close() }
+func (o *Output) Fd() uintptr                { return o.f.Fd() }
+
+// Copy puts text on the clipboard. The OSC 52 sequence is written to w,
+// the program's Output, and skipped when w isn't a terminal. Copy fails
+// only when neither OSC 52 nor a local clipboard tool could be used.
+func Copy(w io.Writer, text string) error {
+	oscErr := writeOSC52(w, text)
+	if remote() {
+		return oscErr
+	}
+	toolErr := copyWithTool(text)
+	if oscErr != nil && toolErr != nil {
+		return fmt.Errorf("%v; %v", oscErr, toolErr)
+	}
+	return nil
+}
+
+// writeOSC52 sends the sequence to w, wrapped for tmux passthrough when
+// running inside tmux.
+func writeOSC52(w io.Writer, text string) error {
+	if f, ok := w.(interface{ Fd() uintptr }); !ok || !term.IsTerminal(int(f.Fd())) {
+		return errors.New("output is not a terminal")
+	}
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	if os.Getenv("TMUX") != "" {
+		seq = "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+	_, err := io.WriteString(w, seq)
+	return err
+}
+
+// remote reports whether this is an SSH session, where a local tool would
+// reach the wrong machine's clipboard.
+func remote() bool {
+	return os.Getenv("SSH_CONNECTION") != "" || os.Getenv("SSH_TTY") != ""
+}
+
+// copyWithTool pipes text to the first clipboard tool that fits the
+// session.
+func copyWithTool(text string) error {
+	var tools [][]string
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		tools = append(tools, []string{"wl-copy"})
+	}
+	if os.Getenv("DISPLAY") != "" {
+		tools = append(tools, []string{"xclip", "-selection", "clipboard"}, []string{"xsel", "--clipboard", "--input"})
+	}
+	tools = append(tools, []string{"pbcopy"})
+
+	for _, args := range tools {
+		path, err := exec.LookPath(args[0])
+		if err != nil {
+			continue
+		}
+		cmd := exec.Command(path, args[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		return cmd.Run()
+	}
+	return errors.New("no clipboard tool found (wl-copy, xclip, xsel or pbcopy)")
+}
@@ -1,22 +1,63 @@
 package config
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/benji/cogito/internal/provider"
+	"github.com/benji/cogito/internal/redact"
+	"github.com/benji/cogito/internal/secrets"
 )
 
 type Config struct {
-	Provider           string            `json:"provider"`
-	APIKeys            map[string]string `json:"api_keys"`
-	BaseURL            string            `json:"base_url"`
-	DefaultModel       string            `json:"default_model"`
-	AvailableModels    []string          `json:"available_models"`
-	Theme              ThemeConfig       `json:"theme"`
-	Context            ContextConfig     `json:"context"`
-	ClearScreen        bool              `json:"clear_screen"`
-	Position           string            `json:"position"`
-	CustomInstructions string            `json:"custom_instructions"`
-	MaxResponseLines   int               `json:"max_response_lines"`
+	Provider            string             `json:"provider"`
+	APIKeys             map[string]string  `json:"api_keys,omitempty"`
+	APIKeyCmd           map[string]string  `json:"api_key_cmd,omitempty"`
+	Secrets             SecretsConfig      `json:"secrets"`
+	BaseURL             string             `json:"base_url"`
+	DefaultModel        string             `json:"default_model"`
+	AvailableModels     []string           `json:"available_models"`
+	Theme               ThemeConfig        `json:"theme"`
+	Context             ContextConfig      `json:"context"`
+	ClearScreen         bool               `json:"clear_screen"`
+	Position            string             `json:"position"`
+	CustomInstructions  string             `json:"custom_instructions"`
+	MaxResponseLines    int                `json:"max_response_lines"`
+	RawOutput           bool               `json:"raw_output"`
+	AppendCommandOutput bool               `json:"append_command_output"`
+	Ollama              OllamaConfig       `json:"ollama"`
+	Redaction           RedactionConfig    `json:"redaction"`
+	History             HistoryConfig      `json:"history"`
+	Profiles            map[string]Profile `json:"profiles,omitempty"`
+
+	// profile is the selected profile and base the settings it was
+	// applied to; keyRef is the profile's secret store entry, if any.
+	profile string
+	base    *Config
+	keyRef  string
+
+	// externalKeys are the keys that came from the environment or
+	// api_key_cmd; Save keeps them out of the secret store.
+	externalKeys map[string]string
+	// keyErrs records api_key_cmd failures for CheckAPIKey to report.
+	keyErrs map[string]error
+}
+
+// SecretsConfig selects where API keys are stored: "auto" (the OS keyring,
+// or a 0600 file when there is none), "keyring", "file" or "age".
+// AgeIdentity is the age identity file that decrypts the age store; it is
+// required for that store and $COGITO_AGE_IDENTITY overrides it.
+type SecretsConfig struct {
+	Store       string `json:"store"`
+	AgeIdentity string `json:"age_identity"`
 }
 
 type ThemeConfig struct {
@@ -24,16 +65,45 @@ type ThemeConfig struct {
 	BorderStyle string `json:"border_style"`
 }
 
+// OllamaConfig holds settings only the native Ollama provider understands.
+// Options are sent as the request "options" object (num_ctx, temperature...)
+// and ModelOptions override them per model name.
+type OllamaConfig struct {
+	KeepAlive    string                    `json:"keep_alive"`
+	PullMissing  bool                      `json:"pull_missing"`
+	Options      map[string]any            `json:"options"`
+	ModelOptions map[string]map[string]any `json:"model_options"`
+}
+
+// RedactionConfig controls masking of secrets before anything is sent to
+// the provider. Rules are extra patterns on top of the built-in ones.
+type RedactionConfig struct {
+	Enabled bool          `json:"enabled"`
+	Rules   []redact.Rule `json:"rules"`
+}
+
+// HistoryConfig controls the prompt history kept at HistoryPath.
+type HistoryConfig struct {
+	Enabled    bool `json:"enabled"`
+	MaxEntries int  `json:"max_entries"`
+}
+
 type ContextConfig struct {
 	IncludeCWD          bool `json:"include_cwd"`
 	IncludeShellHistory bool `json:"include_shell_history"`
+	ShellHistoryLines   int  `json:"shell_history_lines"`
+	IncludeGitBranch    bool `json:"include_git_branch"`
+	IncludeGitStatus    bool `json:"include_git_status"`
+	IncludeGitLog       bool `json:"include_git_log"`
+	GitLogCount         int  `json:"git_log_count"`
+	IncludeGitDiff      bool `json:"include_git_diff"`
 }
 
 func DefaultConfig() Config {
 	return Config{
-		Provider: "openai",
-		APIKeys:  map[string]string{"openai": ""},
-		BaseURL:  "",
+		Provider:        "openai",
+		APIKeys:         map[string]string{"openai": ""},
+		BaseURL:         "",
 		DefaultModel:    "gpt-4o-mini",
 		AvailableModels: []string{"gpt-4o-mini", "gpt-4o", "gpt-4-turbo"},
 		Theme: ThemeConfig{
@@ -43,24 +113,47 @@ func DefaultConfig() Config {
 		Context: ContextConfig{
 			IncludeCWD:          true,
 			IncludeShellHistory: false,
+			ShellHistoryLines:   10,
+			GitLogCount:         5,
 		},
 		ClearScreen:      false,
 		Position:         "bottom",
 		MaxResponseLines: 8,
+		Redaction:        RedactionConfig{Enabled: true},
+		History:          HistoryConfig{Enabled: true, MaxEntries: 1000},
+		Secrets:          SecretsConfig{Store: secrets.BackendAuto},
 	}
 }
 
+// apiKeyEnvVars maps provider names to the environment variable that
+// overrides their stored API key.
+var apiKeyEnvVars = map[string]string{
+	"openai":    "OPENAI_API_KEY",
+	"anthropic": "ANTHROPIC_API_KEY",
+}
+
+// APIKeyEnvVar returns the environment variable consulted for the given
+// provider's API key, or "" if it has none.
+func APIKeyEnvVar(provider string) string {
+	return apiKeyEnvVars[provider]
+}
+
 func Load() (Config, error) {
 	cfg := DefaultConfig()
 
 	path, err := ConfigFilePath()
 	if err != nil {
+		applyEnvOverrides(&cfg)
 		return cfg, nil
 	}
 
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
+			if err := cfg.loadSecrets(path); err != nil {
+				return cfg, err
+			}
+			applyEnvOverrides(&cfg)
 			return cfg, nil
 		}
 		return cfg, err
@@ -70,29 +163,187 @@ func Load() (Config, error) {
 		return DefaultConfig(), err
 	}
 
-	// Check env var override
-	if envKey := os.Getenv("OPENAI_API_KEY"); envKey != "" {
-		if cfg.APIKeys == nil {
-			cfg.APIKeys = make(map[string]string)
+	if err := cfg.loadSecrets(path); err != nil {
+		return cfg, err
+	}
+	applyEnvOverrides(&cfg)
+	return cfg, nil
+}
+
+// loadSecrets reads API keys from the secret store and api_key_cmd.
+// Keys still stored in config.json by older versions are moved to the
+// store and the file is rewritten without them.
+func (c *Config) loadSecrets(path string) error {
+	store, err := c.SecretStore()
+	if err != nil {
+		return err
+	}
+
+	migrated := false
+	for name, key := range c.APIKeys {
+		if key == "" {
+			continue
+		}
+		if err := store.Set(name, key); err != nil {
+			return fmt.Errorf("moving the %s API key to the %s store: %w", name, store.Name(), err)
+		}
+		migrated = true
+	}
+	if migrated {
+		if err := c.writeFile(path); err != nil {
+			return err
 		}
-		cfg.APIKeys["openai"] = envKey
 	}
 
-	return cfg, nil
+	if c.APIKeys == nil {
+		c.APIKeys = make(map[string]string)
+	}
+	for _, name := range provider.Names() {
+		key, err := store.Get(name)
+		switch {
+		case err == nil:
+			c.APIKeys[name] = key
+		case !errors.Is(err, secrets.ErrNotFound):
+			return fmt.Errorf("reading the %s API key from the %s store: %w", name, store.Name(), err)
+		}
+	}
+
+	for name, command := range c.APIKeyCmd {
+		if strings.TrimSpace(command) == "" {
+			continue
+		}
+		key, err := runKeyCommand(command)
+		if err != nil {
+			c.setKeyErr(name, fmt.Errorf("api_key_cmd for %s failed: %w", name, err))
+			continue
+		}
+		c.setExternalKey(name, key)
+	}
+	return nil
 }
 
+// runKeyCommand runs an api_key_cmd such as "pass show openai" and returns
+// the first line it prints.
+func runKeyCommand(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), keyCommandTimeout)
+	defer cancel()
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	key, _, _ := strings.Cut(string(out), "\n")
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return "", errors.New("printed nothing")
+	}
+	return key, nil
+}
+
+// keyCommandTimeout bounds api_key_cmd, which may wait on a gpg agent.
+const keyCommandTimeout = 30 * time.Second
+
+func (c *Config) setExternalKey(name, key string) {
+	if c.APIKeys == nil {
+		c.APIKeys = make(map[string]string)
+	}
+	if c.externalKeys == nil {
+		c.externalKeys = make(map[string]string)
+	}
+	c.APIKeys[name] = key
+	c.externalKeys[name] = key
+}
+
+func (c *Config) setKeyErr(name string, err error) {
+	if c.keyErrs == nil {
+		c.keyErrs = make(map[string]error)
+	}
+	c.keyErrs[name] = err
+}
+
+func applyEnvOverrides(cfg *Config) {
+	for name, env := range apiKeyEnvVars {
+		if envKey := os.Getenv(env); envKey != "" {
+			cfg.setExternalKey(name, envKey)
+			delete(cfg.keyErrs, name)
+		}
+	}
+}
+
+// EnvAgeIdentity names the age identity file, overriding
+// secrets.age_identity.
+const EnvAgeIdentity = "COGITO_AGE_IDENTITY"
+
+// SecretStore opens the API key store selected by c.Secrets.
+func (c Config) SecretStore() (secrets.Store, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	identity := os.Getenv(EnvAgeIdentity)
+	if identity == "" {
+		identity = c.Secrets.AgeIdentity
+	}
+	if c.Secrets.Store == secrets.BackendAge && identity == "" {
+		return nil, fmt.Errorf("the age secret store needs an identity: set secrets.age_identity or $%s to a file made with age-keygen", EnvAgeIdentity)
+	}
+	return secrets.Open(c.Secrets.Store, secrets.Options{
+		FilePath:    filepath.Join(dir, "secrets.json"),
+		AgePath:     filepath.Join(dir, "secrets.age"),
+		AgeIdentity: identity,
+	})
+}
+
+// Save writes API keys to the secret store and everything else to
+// config.json. Keys from the environment or api_key_cmd aren't stored.
 func (c Config) Save() error {
 	path, err := ConfigFilePath()
 	if err != nil {
 		return err
 	}
 
-	data, err := json.MarshalIndent(c, "", "  ")
+	store, err := c.SecretStore()
 	if err != nil {
 		return err
 	}
+	for name, key := range c.APIKeys {
+		if ext, ok := c.externalKeys[name]; ok && ext == key {
+			continue
+		}
+		if name == c.Provider && c.keyRef != "" {
+			name = c.keyRef
+		}
+		if key == "" {
+			err = store.Delete(name)
+		} else {
+			err = store.Set(name, key)
+		}
+		if err != nil {
+			return fmt.Errorf("saving the %s API key to the %s store: %w", name, store.Name(), err)
+		}
+	}
 
-	return os.WriteFile(path, data, 0o644)
+	return c.fileConfig().writeFile(path)
+}
+
+// writeFile writes c to path without its API keys.
+func (c Config) writeFile(path string) error {
+	c.APIKeys = nil
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return err
+	}
+	// WriteFile keeps the mode of an existing file; older versions
+	// created it world-readable.
+	return os.Chmod(path, 0o600)
 }
 
 func (c Config) APIKey() string {
@@ -101,3 +352,43 @@ func (c Config) APIKey() string {
 	}
 	return ""
 }
+
+// ProviderConfig returns the typed config block for the selected backend.
+func (c Config) ProviderConfig() provider.Config {
+	return provider.Config{
+		APIKey:  c.APIKey(),
+		Model:   c.DefaultModel,
+		BaseURL: c.BaseURL,
+		Ollama: provider.OllamaOptions{
+			KeepAlive:    c.Ollama.KeepAlive,
+			PullMissing:  c.Ollama.PullMissing,
+			Options:      c.Ollama.Options,
+			ModelOptions: c.Ollama.ModelOptions,
+		},
+	}
+}
+
+// Redactor builds the secret redactor configured by c.Redaction, or nil
+// when redaction is disabled. An invalid custom rule is reported in err
+// but the returned redactor still applies the others.
+func (c Config) Redactor() (*redact.Redactor, error) {
+	if !c.Redaction.Enabled {
+		return nil, nil
+	}
+	return redact.New(c.Redaction.Rules)
+}
+
+// CheckAPIKey returns an error when the selected provider needs an API key
+// and none is configured.
+func (c Config) CheckAPIKey() error {
+	if !provider.RequiresAPIKey(c.Provider) || c.APIKey() != "" {
+		return nil
+	}
+	if err := c.keyErrs[c.Provider]; err != nil {
+		return err
+	}
+	if env := APIKeyEnvVar(c.Provider); env != "" {
+		return fmt.Errorf("no API key set — run /settings or set %s", env)
+	}
+	return fmt.Errorf("no API key set — run /settings")
+}
@@ -24,3 +24,33 @@ func ConfigFilePath() (string, error) {
 	}
 	return filepath.Join(dir, "config.json"), nil
 }
+
+func SessionsDir() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "sessions")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// ModelsCachePath is where model lists fetched from providers are cached.
+func ModelsCachePath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "models-cache.json"), nil
+}
+
+// HistoryPath is where prompts typed into the TUI are kept.
+func HistoryPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "history.jsonl"), nil
+}
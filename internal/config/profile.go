@@ -0,0 +1,156 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/benji/cogito/internal/secrets"
+)
+
+// DefaultProfile names the top-level settings, used when no profile is
+// selected.
+const DefaultProfile = "default"
+
+// Profile is a named set of overrides for the top-level settings, e.g. a
+// work OpenAI account, an OpenRouter key and a local Ollama. Empty fields
+// keep the top-level value.
+type Profile struct {
+	Provider string `json:"provider,omitempty"`
+	BaseURL  string `json:"base_url,omitempty"`
+	// APIKeyRef names the secret store entry holding this profile's key,
+	// so two profiles on one provider can use different keys.
+	APIKeyRef          string `json:"api_key_ref,omitempty"`
+	APIKeyCmd          string `json:"api_key_cmd,omitempty"`
+	Model              string `json:"model,omitempty"`
+	CustomInstructions string `json:"custom_instructions,omitempty"`
+	AccentColor        string `json:"accent_color,omitempty"`
+}
+
+// ProfileName returns the selected profile, or DefaultProfile.
+func (c Config) ProfileName() string {
+	if c.profile == "" {
+		return DefaultProfile
+	}
+	return c.profile
+}
+
+// ProfileNames lists DefaultProfile and the configured profiles, sorted.
+func (c Config) ProfileNames() []string {
+	names := slices.Sorted(maps.Keys(c.Profiles))
+	return append([]string{DefaultProfile}, names...)
+}
+
+// UseProfile returns c with profile name applied on top of the top-level
+// settings, replacing any profile selected before. DefaultProfile (or "")
+// goes back to the top-level settings.
+func (c Config) UseProfile(name string) (Config, error) {
+	c = c.withoutProfile()
+	if name == "" || name == DefaultProfile {
+		return c, nil
+	}
+	p, ok := c.Profiles[name]
+	if !ok {
+		return c, fmt.Errorf("no profile %q", name)
+	}
+
+	base := c
+	c.base = &base
+	c.profile = name
+	c.APIKeys = maps.Clone(c.APIKeys)
+	c.externalKeys = maps.Clone(c.externalKeys)
+	c.keyErrs = maps.Clone(c.keyErrs)
+
+	if p.Provider != "" {
+		c.Provider = p.Provider
+	}
+	if p.BaseURL != "" {
+		c.BaseURL = p.BaseURL
+	}
+	if p.Model != "" {
+		c.DefaultModel = p.Model
+	}
+	if p.CustomInstructions != "" {
+		c.CustomInstructions = p.CustomInstructions
+	}
+	if p.AccentColor != "" {
+		c.Theme.AccentColor = p.AccentColor
+	}
+
+	switch {
+	case p.APIKeyCmd != "":
+		key, err := runKeyCommand(p.APIKeyCmd)
+		if err != nil {
+			delete(c.APIKeys, c.Provider)
+			c.setKeyErr(c.Provider, fmt.Errorf("api_key_cmd for profile %s failed: %w", name, err))
+			break
+		}
+		c.setExternalKey(c.Provider, key)
+	case p.APIKeyRef != "":
+		c.keyRef = p.APIKeyRef
+		store, err := c.SecretStore()
+		if err != nil {
+			return base, err
+		}
+		key, err := store.Get(p.APIKeyRef)
+		if err != nil && !errors.Is(err, secrets.ErrNotFound) {
+			return base, fmt.Errorf("reading key %s for profile %s: %w", p.APIKeyRef, name, err)
+		}
+		c.APIKeys[c.Provider] = key
+		delete(c.externalKeys, c.Provider)
+	}
+	return c, nil
+}
+
+// withoutProfile undoes UseProfile, keeping changes made since to the
+// settings profiles don't override.
+func (c Config) withoutProfile() Config {
+	if c.base == nil {
+		return c
+	}
+	b := c.base
+	c.Provider = b.Provider
+	c.BaseURL = b.BaseURL
+	c.DefaultModel = b.DefaultModel
+	c.CustomInstructions = b.CustomInstructions
+	c.Theme.AccentColor = b.Theme.AccentColor
+	c.APIKeys = b.APIKeys
+	c.externalKeys = b.externalKeys
+	c.keyErrs = b.keyErrs
+	c.base = nil
+	c.profile = ""
+	c.keyRef = ""
+	return c
+}
+
+// fileConfig returns what config.json should hold. With a profile
+// selected, the settings it overrides are saved into the profile and the
+// top-level ones are left as they were.
+func (c Config) fileConfig() Config {
+	if c.base == nil {
+		return c
+	}
+	b := c.base
+	p := c.Profiles[c.profile]
+	p.Provider = override(c.Provider, b.Provider, p.Provider)
+	p.BaseURL = override(c.BaseURL, b.BaseURL, p.BaseURL)
+	p.Model = override(c.DefaultModel, b.DefaultModel, p.Model)
+	p.CustomInstructions = override(c.CustomInstructions, b.CustomInstructions, p.CustomInstructions)
+	p.AccentColor = override(c.Theme.AccentColor, b.Theme.AccentColor, p.AccentColor)
+
+	out := c.withoutProfile()
+	out.Profiles = maps.Clone(c.Profiles)
+	out.Profiles[c.profile] = p
+	return out
+}
+
+// override returns the profile value to save for a setting now at cur:
+// empty (inherit) when it matches the top-level base and the profile
+// didn't set it before.
+func override(cur, base, prev string) string {
+	if cur == base && prev == "" {
+		return ""
+	}
+	return cur
+}
@@ -0,0 +1,38 @@
+package context
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FormatAttachment wraps content in a fenced block headed by label, for
+// inclusion in a user message. truncated adds a notice that the content
+// was cut to fit the size limit.
+func FormatAttachment(label, content string, truncated bool) string {
+	fence := "```"
+	for strings.Contains(content, fence) {
+		fence += "`"
+	}
+
+	var b strings.Builder
+	b.WriteString(label + ":\n")
+	b.WriteString(fence + "\n")
+	b.WriteString(strings.TrimRight(content, "\n"))
+	b.WriteString("\n" + fence + "\n")
+	if truncated {
+		b.WriteString("[truncated at the size limit]\n")
+	}
+	return b.String()
+}
+
+// FormatSize renders a byte count as B/KB/MB for status messages.
+func FormatSize(n int) string {
+	switch {
+	case n >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+	case n >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(n)/1024)
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
@@ -0,0 +1,176 @@
+package context
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CommitDiffBudget is how much of the staged diff is sent when asking for
+// a commit message. Larger diffs are summarised per file.
+const CommitDiffBudget = 24 * 1024
+
+// ErrNothingStaged is returned by StagedChanges when the index matches HEAD.
+var ErrNothingStaged = errors.New("nothing staged — git add the changes to commit first")
+
+// StagedChanges returns the full staged diff and its --stat summary.
+func StagedChanges() (diff, stat string, err error) {
+	if !InGitRepo() {
+		return "", "", errors.New("not inside a git repository")
+	}
+	diff, _, err = GitStagedDiff(0)
+	if err != nil {
+		return "", "", err
+	}
+	if strings.TrimSpace(diff) == "" {
+		return "", "", ErrNothingStaged
+	}
+	stat, err = runGit("diff", "--cached", "--stat", "--no-color")
+	if err != nil {
+		return "", "", err
+	}
+	return diff, stat, nil
+}
+
+// fileDiff is one "diff --git" section of a unified diff.
+type fileDiff struct {
+	path string
+	text string
+}
+
+// splitDiff breaks a unified diff into per-file sections.
+func splitDiff(diff string) []fileDiff {
+	var files []fileDiff
+	for len(diff) > 0 {
+		end := strings.Index(diff, "\ndiff --git ")
+		if end < 0 {
+			end = len(diff)
+		} else {
+			end++
+		}
+		text := diff[:end]
+		diff = diff[end:]
+
+		path := ""
+		if header, _, _ := strings.Cut(text, "\n"); strings.HasPrefix(header, "diff --git ") {
+			if i := strings.LastIndex(header, " b/"); i >= 0 {
+				path = header[i+3:]
+			}
+		}
+		files = append(files, fileDiff{path: path, text: text})
+	}
+	return files
+}
+
+// summariseFileDiff keeps the header and the first hunk lines of a file's
+// diff within budget bytes and notes how many lines were left out.
+func summariseFileDiff(f fileDiff, budget int) string {
+	var added, removed int
+	lines := strings.Split(f.text, "\n")
+	for _, l := range lines {
+		switch {
+		case strings.HasPrefix(l, "+++"), strings.HasPrefix(l, "---"):
+		case strings.HasPrefix(l, "+"):
+			added++
+		case strings.HasPrefix(l, "-"):
+			removed++
+		}
+	}
+
+	var b strings.Builder
+	kept := 0
+	for _, l := range lines {
+		if b.Len()+len(l)+1 > budget {
+			break
+		}
+		b.WriteString(l + "\n")
+		kept++
+	}
+	if omitted := len(lines) - kept; omitted > 0 {
+		fmt.Fprintf(&b, "[%s: +%d -%d lines in total, %d lines omitted]\n", f.path, added, removed, omitted)
+	}
+	return b.String()
+}
+
+// fitDiff returns diff unchanged when it fits in budget. Otherwise each
+// file gets an equal share of the budget, with the share of small files
+// passed on to the larger ones.
+func fitDiff(diff string, budget int) (string, bool) {
+	if len(diff) <= budget {
+		return diff, false
+	}
+	files := splitDiff(diff)
+	order := make([]int, len(files))
+	for i := range order {
+		order[i] = i
+	}
+	// Hand out the budget smallest file first.
+	sort.SliceStable(order, func(a, b int) bool {
+		return len(files[order[a]].text) < len(files[order[b]].text)
+	})
+
+	parts := make([]string, len(files))
+	left := budget
+	for n, i := range order {
+		share := left / (len(order) - n)
+		if len(files[i].text) <= share {
+			parts[i] = files[i].text
+		} else {
+			parts[i] = summariseFileDiff(files[i], share)
+		}
+		left -= len(parts[i])
+	}
+	return strings.Join(parts, ""), true
+}
+
+// BuildCommitPrompt asks for a Conventional Commits message for the staged
+// diff, cutting it down to CommitDiffBudget when needed.
+func BuildCommitPrompt(diff, stat string) string {
+	fitted, summarised := fitDiff(diff, CommitDiffBudget)
+
+	var b strings.Builder
+	b.WriteString("Write a git commit message for the staged changes below, following the Conventional Commits format:\n")
+	b.WriteString("- a subject line of at most 72 characters: type(optional scope): summary, imperative mood, no trailing period\n")
+	b.WriteString("- then, only if the change needs explaining, a blank line and a short body wrapped at 72 columns saying what changed and why\n")
+	b.WriteString("Reply with the commit message only — no code fences, quotes or commentary.\n\n")
+	b.WriteString(FormatAttachment("Files changed", stat, false))
+	label := "Staged diff"
+	if summarised {
+		label += " (large files shortened)"
+	}
+	b.WriteString(FormatAttachment(label, fitted, false))
+	return b.String()
+}
+
+// BuildCommitSystemMessage is the system prompt for commit mode. The
+// prompt from BuildCommitPrompt already carries the staged diff, so none
+// of the shell or git context of BuildSystemMessage is added; only the
+// user's custom instructions are.
+func BuildCommitSystemMessage(customInstructions string) string {
+	msg := "You are Cogito, writing git commit messages for the user's staged changes. " +
+		"Reply with the commit message only."
+	if strings.TrimSpace(customInstructions) != "" {
+		msg += "\n\nUser's custom instructions:\n" + customInstructions
+	}
+	return msg
+}
+
+// CleanCommitMessage strips the code fence or quotes a model may wrap
+// around a commit message despite being asked not to.
+func CleanCommitMessage(msg string) string {
+	msg = strings.TrimSpace(msg)
+	if strings.HasPrefix(msg, "```") && strings.HasSuffix(msg, "```") {
+		msg = strings.TrimSuffix(msg, "```")
+		if _, rest, ok := strings.Cut(msg, "\n"); ok {
+			msg = rest
+		} else {
+			msg = ""
+		}
+	}
+	msg = strings.TrimSpace(msg)
+	if len(msg) >= 2 && msg[0] == '"' && msg[len(msg)-1] == '"' && !strings.Contains(msg, "\n") {
+		msg = msg[1 : len(msg)-1]
+	}
+	return strings.TrimSpace(msg)
+}
@@ -0,0 +1,76 @@
+package context
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// maxFixStderr caps how much captured stderr is sent with a fix request.
+const maxFixStderr = 4 * 1024
+
+// Environment variables exported by the shell-init hooks after every
+// command.
+const (
+	EnvLastCommand = "COGITO_LAST_COMMAND"
+	EnvLastStatus  = "COGITO_LAST_STATUS"
+	EnvStderrFile  = "COGITO_STDERR_FILE"
+)
+
+// FailedCommand describes the command "cogito fix" should repair.
+// Status is -1 when the exit status isn't known.
+type FailedCommand struct {
+	Command string
+	Status  int
+	Stderr  string
+}
+
+// Failed reports whether the shell recorded a non-zero exit status. An
+// unknown status doesn't count as a failure.
+func (fc FailedCommand) Failed() bool {
+	return fc.Status > 0
+}
+
+// LastCommandFromEnv reads the last command recorded by the shell hook.
+// ok is false when no command was recorded.
+func LastCommandFromEnv() (fc FailedCommand, ok bool) {
+	fc.Command = strings.TrimSpace(os.Getenv(EnvLastCommand))
+	if fc.Command == "" {
+		return fc, false
+	}
+	status, err := strconv.Atoi(os.Getenv(EnvLastStatus))
+	if err != nil {
+		status = -1
+	}
+	fc.Status = status
+	if path := os.Getenv(EnvStderrFile); path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			fc.Stderr = string(data)
+		}
+	}
+	return fc, true
+}
+
+// BuildFixPrompt asks the model for a corrected version of fc.
+func BuildFixPrompt(fc FailedCommand) string {
+	var b strings.Builder
+	b.WriteString("My last shell command failed.\n\n")
+	fmt.Fprintf(&b, "Command:\n```\n%s\n```\n", fc.Command)
+	if fc.Status >= 0 {
+		fmt.Fprintf(&b, "Exit status: %d\n", fc.Status)
+	}
+
+	stderr := strings.TrimSpace(fc.Stderr)
+	if len(stderr) > maxFixStderr {
+		stderr = "[...truncated]\n" + stderr[len(stderr)-maxFixStderr:]
+	}
+	if stderr != "" {
+		fmt.Fprintf(&b, "\nError output:\n```\n%s\n```\n", stderr)
+	}
+
+	b.WriteString("\nReply with the corrected command in a single ```bash code block, " +
+		"then one or two sentences explaining what was wrong. " +
+		"If the command cannot be fixed, say so briefly instead.")
+	return b.String()
+}
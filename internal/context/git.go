@@ -0,0 +1,157 @@
+package context
+
+import (
+	ctxpkg "context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/benji/cogito/internal/config"
+)
+
+const (
+	gitTimeout        = 2 * time.Second
+	maxGitStatusLines = 20
+	maxGitDiffBytes   = 16 * 1024
+)
+
+// GitInfo is what was gathered about the repository containing the
+// working directory. Empty fields were either disabled or unavailable.
+type GitInfo struct {
+	Branch  string
+	Status  string
+	Commits []string
+	Diff    string
+}
+
+// runGit runs git with args in the working directory and returns its
+// trimmed stdout.
+func runGit(args ...string) (string, error) {
+	ctx, cancel := ctxpkg.WithTimeout(ctxpkg.Background(), gitTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "git", args...).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+// InGitRepo reports whether the working directory is inside a git work tree.
+func InGitRepo() bool {
+	out, err := runGit("rev-parse", "--is-inside-work-tree")
+	return err == nil && out == "true"
+}
+
+// GitBranch returns the current branch, or "detached at <sha>" when HEAD
+// isn't on a branch.
+func GitBranch() (string, error) {
+	branch, err := runGit("rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		// A fresh repository has no commits yet, so HEAD doesn't resolve.
+		return runGit("symbolic-ref", "--short", "HEAD")
+	}
+	if branch == "HEAD" {
+		sha, err := runGit("rev-parse", "--short", "HEAD")
+		if err != nil {
+			return "", err
+		}
+		return "detached at " + sha, nil
+	}
+	return branch, nil
+}
+
+// GitStatus summarises `git status --porcelain`: a count line followed by
+// the first few entries. It returns "" for a clean tree.
+func GitStatus() (string, error) {
+	out, err := runGit("status", "--porcelain")
+	if err != nil || out == "" {
+		return "", err
+	}
+
+	lines := strings.Split(out, "\n")
+	var staged, modified, untracked int
+	for _, l := range lines {
+		if len(l) < 2 {
+			continue
+		}
+		switch {
+		case l[:2] == "??":
+			untracked++
+		default:
+			if l[0] != ' ' {
+				staged++
+			}
+			if l[1] != ' ' {
+				modified++
+			}
+		}
+	}
+
+	summary := fmt.Sprintf("%d staged, %d modified, %d untracked", staged, modified, untracked)
+	shown := lines
+	if len(shown) > maxGitStatusLines {
+		shown = shown[:maxGitStatusLines]
+	}
+	summary += "\n" + strings.Join(shown, "\n")
+	if extra := len(lines) - len(shown); extra > 0 {
+		summary += fmt.Sprintf("\n... and %d more", extra)
+	}
+	return summary, nil
+}
+
+// GitLog returns the subjects of the last n commits, newest first.
+func GitLog(n int) ([]string, error) {
+	if n <= 0 {
+		n = 5
+	}
+	out, err := runGit("log", "-n", fmt.Sprint(n), "--format=%s")
+	if err != nil || out == "" {
+		return nil, err
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// GitStagedDiff returns `git diff --cached`, cut at maxBytes.
+func GitStagedDiff(maxBytes int) (diff string, truncated bool, err error) {
+	out, err := runGit("diff", "--cached", "--no-color")
+	if err != nil {
+		return "", false, err
+	}
+	if maxBytes > 0 && len(out) > maxBytes {
+		cut := strings.LastIndexByte(out[:maxBytes], '\n')
+		if cut <= 0 {
+			cut = maxBytes
+		}
+		return out[:cut], true, nil
+	}
+	return out, false, nil
+}
+
+// ReadGitInfo gathers the parts of the repository state enabled in ctx.
+// ok is false when nothing is enabled or the working directory isn't in a
+// git repository.
+func ReadGitInfo(ctx config.ContextConfig) (info GitInfo, ok bool) {
+	if !ctx.IncludeGitBranch && !ctx.IncludeGitStatus && !ctx.IncludeGitLog && !ctx.IncludeGitDiff {
+		return info, false
+	}
+	if !InGitRepo() {
+		return info, false
+	}
+	if ctx.IncludeGitBranch {
+		info.Branch, _ = GitBranch()
+	}
+	if ctx.IncludeGitStatus {
+		info.Status, _ = GitStatus()
+	}
+	if ctx.IncludeGitLog {
+		info.Commits, _ = GitLog(ctx.GitLogCount)
+	}
+	if ctx.IncludeGitDiff {
+		d, truncated, err := GitStagedDiff(maxGitDiffBytes)
+		if err == nil && strings.TrimSpace(d) != "" {
+			info.Diff = FormatAttachment("Staged diff", d, truncated)
+		}
+	}
+	return info, true
+}
@@ -0,0 +1,178 @@
+package context
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/benji/cogito/internal/config"
+)
+
+// newRepo creates a git repository in a temporary directory and makes it
+// the working directory for the test.
+func newRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	git(t, "init", "-q", "-b", "main")
+	return dir
+}
+
+func git(t *testing.T, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func commit(t *testing.T, dir, name, subject string) {
+	t.Helper()
+	writeFile(t, dir, name, subject+"\n")
+	git(t, "add", name)
+	git(t, "commit", "-q", "-m", subject)
+}
+
+func TestGitBranch(t *testing.T) {
+	dir := newRepo(t)
+
+	// No commits yet: HEAD is unborn but the branch is known.
+	if got, err := GitBranch(); err != nil || got != "main" {
+		t.Errorf("unborn branch = %q, %v; want main", got, err)
+	}
+
+	commit(t, dir, "a.txt", "first")
+	git(t, "checkout", "-q", "-b", "feature/x")
+	if got, err := GitBranch(); err != nil || got != "feature/x" {
+		t.Errorf("branch = %q, %v; want feature/x", got, err)
+	}
+
+	sha := git(t, "rev-parse", "--short", "HEAD")
+	git(t, "checkout", "-q", "--detach")
+	if got, err := GitBranch(); err != nil || got != "detached at "+sha {
+		t.Errorf("detached = %q, %v; want detached at %s", got, err, sha)
+	}
+}
+
+func TestGitStatus(t *testing.T) {
+	dir := newRepo(t)
+	commit(t, dir, "tracked.txt", "first")
+
+	if got, err := GitStatus(); err != nil || got != "" {
+		t.Errorf("clean tree = %q, %v; want empty", got, err)
+	}
+
+	writeFile(t, dir, "tracked.txt", "changed\n")
+	writeFile(t, dir, "staged.txt", "new\n")
+	git(t, "add", "staged.txt")
+	writeFile(t, dir, "untracked.txt", "?\n")
+
+	got, err := GitStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "1 staged, 1 modified, 1 untracked"; !strings.HasPrefix(got, want+"\n") {
+		t.Errorf("status = %q, want it to start with %q", got, want)
+	}
+	for _, name := range []string{"tracked.txt", "staged.txt", "untracked.txt"} {
+		if !strings.Contains(got, name) {
+			t.Errorf("status %q doesn't list %s", got, name)
+		}
+	}
+}
+
+func TestGitStatusCapsEntries(t *testing.T) {
+	dir := newRepo(t)
+	for i := range maxGitStatusLines + 5 {
+		writeFile(t, dir, fmt.Sprintf("f%02d.txt", i), "x\n")
+	}
+	got, err := GitStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(got, "\n")
+	if len(lines) != maxGitStatusLines+2 || lines[len(lines)-1] != "... and 5 more" {
+		t.Errorf("status has %d lines ending %q; want %d ending with the overflow note",
+			len(lines), lines[len(lines)-1], maxGitStatusLines+2)
+	}
+}
+
+func TestGitLog(t *testing.T) {
+	dir := newRepo(t)
+	for _, s := range []string{"one", "two", "three"} {
+		commit(t, dir, s+".txt", s)
+	}
+	got, err := GitLog(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"three", "two"}; !slices.Equal(got, want) {
+		t.Errorf("log = %q, want %q", got, want)
+	}
+}
+
+func TestGitStagedDiffCap(t *testing.T) {
+	dir := newRepo(t)
+	var b strings.Builder
+	for i := range 200 {
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	writeFile(t, dir, "big.txt", b.String())
+	git(t, "add", "big.txt")
+
+	full, truncated, err := GitStagedDiff(0)
+	if err != nil || truncated || !strings.Contains(full, "+line 199") {
+		t.Fatalf("uncapped diff: truncated=%v err=%v", truncated, err)
+	}
+
+	const limit = 300
+	got, truncated, err := GitStagedDiff(limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !truncated || len(got) > limit {
+		t.Errorf("capped diff is %d bytes (truncated=%v), want at most %d", len(got), truncated, limit)
+	}
+	if !strings.HasPrefix(full, got+"\n") {
+		t.Error("capped diff should end at a line boundary of the full diff")
+	}
+}
+
+func TestReadGitInfo(t *testing.T) {
+	newRepo(t)
+	all := config.ContextConfig{IncludeGitBranch: true, IncludeGitStatus: true, IncludeGitLog: true, IncludeGitDiff: true}
+
+	if _, ok := ReadGitInfo(config.ContextConfig{}); ok {
+		t.Error("nothing enabled should report ok=false")
+	}
+	info, ok := ReadGitInfo(all)
+	if !ok || info.Branch != "main" {
+		t.Errorf("info = %+v, ok=%v; want branch main", info, ok)
+	}
+
+	t.Chdir(t.TempDir())
+	if _, ok := ReadGitInfo(all); ok {
+		t.Error("outside a repository should report ok=false")
+	}
+}
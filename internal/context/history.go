@@ -0,0 +1,221 @@
+package context
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// historyTailBytes bounds how much of a history file is read; only the
+// most recent commands are ever used.
+const historyTailBytes = 256 * 1024
+
+// ReadShellHistory returns up to n of the user's most recent shell
+// commands, oldest first, based on $SHELL (bash, zsh or fish).
+func ReadShellHistory(n int) ([]string, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	switch filepath.Base(os.Getenv("SHELL")) {
+	case "zsh":
+		return ReadZshHistory(historyPath(filepath.Join(home, ".zsh_history")), n)
+	case "fish":
+		dataDir := os.Getenv("XDG_DATA_HOME")
+		if dataDir == "" {
+			dataDir = filepath.Join(home, ".local", "share")
+		}
+		return ReadFishHistory(filepath.Join(dataDir, "fish", "fish_history"), n)
+	default:
+		return ReadBashHistory(historyPath(filepath.Join(home, ".bash_history")), n)
+	}
+}
+
+// historyPath prefers $HISTFILE over the shell's default location.
+func historyPath(fallback string) string {
+	if p := os.Getenv("HISTFILE"); p != "" {
+		return p
+	}
+	return fallback
+}
+
+// ReadBashHistory parses a bash history file, skipping the "#<epoch>"
+// lines written when HISTTIMEFORMAT is set.
+func ReadBashHistory(path string, n int) ([]string, error) {
+	data, err := readTail(path)
+	if err != nil {
+		return nil, err
+	}
+	var cmds []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || isBashTimestamp(line) {
+			continue
+		}
+		cmds = append(cmds, line)
+	}
+	return lastN(cmds, n), nil
+}
+
+func isBashTimestamp(line string) bool {
+	if len(line) < 2 || line[0] != '#' {
+		return false
+	}
+	for _, c := range line[1:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// ReadZshHistory parses a zsh history file in plain or extended
+// (": <epoch>:<duration>;<command>") format. Multi-line commands are
+// continued with a trailing backslash.
+func ReadZshHistory(path string, n int) ([]string, error) {
+	data, err := readTail(path)
+	if err != nil {
+		return nil, err
+	}
+	data = unmetafy(data)
+
+	var (
+		cmds    []string
+		current strings.Builder
+	)
+	for _, line := range strings.Split(string(data), "\n") {
+		if current.Len() == 0 {
+			if strings.HasPrefix(line, ": ") {
+				if i := strings.IndexByte(line, ';'); i >= 0 {
+					line = line[i+1:]
+				}
+			}
+		} else {
+			current.WriteByte('\n')
+		}
+		if strings.HasSuffix(line, "\\") {
+			current.WriteString(strings.TrimSuffix(line, "\\"))
+			continue
+		}
+		current.WriteString(line)
+		if cmd := strings.TrimSpace(current.String()); cmd != "" {
+			cmds = append(cmds, cmd)
+		}
+		current.Reset()
+	}
+	return lastN(cmds, n), nil
+}
+
+// unmetafy reverses zsh's history encoding, where bytes >= 0x83 are
+// written as 0x83 followed by the byte XOR 32.
+func unmetafy(data []byte) []byte {
+	const meta = 0x83
+	if bytes.IndexByte(data, meta) < 0 {
+		return data
+	}
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		if data[i] == meta && i+1 < len(data) {
+			i++
+			out = append(out, data[i]^32)
+			continue
+		}
+		out = append(out, data[i])
+	}
+	return out
+}
+
+// ReadFishHistory parses fish's YAML-like history file, reading the
+// "- cmd: ..." entries.
+func ReadFishHistory(path string, n int) ([]string, error) {
+	data, err := readTail(path)
+	if err != nil {
+		return nil, err
+	}
+	var cmds []string
+	for _, line := range strings.Split(string(data), "\n") {
+		cmd, ok := strings.CutPrefix(line, "- cmd: ")
+		if !ok {
+			continue
+		}
+		if cmd = unescapeFish(cmd); strings.TrimSpace(cmd) != "" {
+			cmds = append(cmds, cmd)
+		}
+	}
+	return lastN(cmds, n), nil
+}
+
+// unescapeFish decodes the \\ and \n escapes fish uses in history entries.
+func unescapeFish(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			switch s[i+1] {
+			case 'n':
+				b.WriteByte('\n')
+				i++
+				continue
+			case '\\':
+				b.WriteByte('\\')
+				i++
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// readTail returns the last historyTailBytes of the file, starting at a
+// line boundary.
+func readTail(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	offset := info.Size() - historyTailBytes
+	if offset <= 0 {
+		return io.ReadAll(f)
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		data = data[i+1:]
+	}
+	return data, nil
+}
+
+// lastN returns the last n commands, collapsing consecutive duplicates.
+func lastN(cmds []string, n int) []string {
+	var deduped []string
+	for _, c := range cmds {
+		if len(deduped) > 0 && deduped[len(deduped)-1] == c {
+			continue
+		}
+		deduped = append(deduped, c)
+	}
+	if len(deduped) > n {
+		deduped = deduped[len(deduped)-n:]
+	}
+	return deduped
+}
@@ -0,0 +1,84 @@
+package context
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestReadHistory(t *testing.T) {
+	tests := []struct {
+		name string
+		read func(path string, n int) ([]string, error)
+		file string
+		n    int
+		want []string
+	}{
+		{
+			name: "bash",
+			read: ReadBashHistory,
+			file: "bash_history",
+			n:    10,
+			want: []string{"ls -la", "cd /tmp", "git status", "make build"},
+		},
+		{
+			name: "bash timestamps",
+			read: ReadBashHistory,
+			file: "bash_history_timestamps",
+			n:    10,
+			want: []string{"ls -la", "cd /tmp", "echo '#not a timestamp'"},
+		},
+		{
+			name: "zsh extended, multiline and metafied",
+			read: ReadZshHistory,
+			file: "zsh_history",
+			n:    10,
+			want: []string{"ls -la", "for f in *; do\n  echo $f\ndone", "echo café", "plain command"},
+		},
+		{
+			name: "fish",
+			read: ReadFishHistory,
+			file: "fish_history",
+			n:    10,
+			want: []string{"ls -la", `echo "a\b"`, "printf 'one\ntwo'"},
+		},
+		{
+			name: "bash last n",
+			read: ReadBashHistory,
+			file: "bash_history",
+			n:    2,
+			want: []string{"git status", "make build"},
+		},
+		{
+			name: "zsh last n",
+			read: ReadZshHistory,
+			file: "zsh_history",
+			n:    1,
+			want: []string{"plain command"},
+		},
+		{
+			name: "fish last n",
+			read: ReadFishHistory,
+			file: "fish_history",
+			n:    2,
+			want: []string{`echo "a\b"`, "printf 'one\ntwo'"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.read(filepath.Join("testdata", tt.file), tt.n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadHistoryMissingFile(t *testing.T) {
+	if _, err := ReadBashHistory(filepath.Join(t.TempDir(), "nope"), 5); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
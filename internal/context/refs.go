@@ -0,0 +1,166 @@
+package context
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// maxFileBytes caps how much of an @file is attached.
+	maxFileBytes = 64 * 1024
+	// maxTreeEntries and maxTreeDepth bound @dir listings.
+	maxTreeEntries = 200
+	maxTreeDepth   = 3
+)
+
+// ErrBinaryFile is returned when an @file reference points at a binary file.
+var ErrBinaryFile = errors.New("binary file")
+
+// skipDirs are never descended into when listing an @dir.
+var skipDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// ParseReferences returns the paths of the @path tokens in query that
+// exist on disk. Tokens must start a word, so "me@host" is not a reference.
+func ParseReferences(query string) []string {
+	var refs []string
+	seen := map[string]bool{}
+	for _, word := range strings.Fields(query) {
+		path, ok := strings.CutPrefix(word, "@")
+		if !ok || path == "" {
+			continue
+		}
+		path = strings.TrimRight(path, ",.;:!?)")
+		if seen[path] {
+			continue
+		}
+		if _, err := os.Stat(path); err == nil {
+			refs = append(refs, path)
+			seen[path] = true
+		}
+	}
+	return refs
+}
+
+// ResolveReference returns a fenced attachment for an @file (its
+// contents) or @dir (a tree listing).
+func ResolveReference(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		tree, truncated := dirTree(path)
+		return FormatAttachment("Directory "+path, tree, truncated), nil
+	}
+
+	data, truncated, err := readFileCapped(path)
+	if err != nil {
+		return "", err
+	}
+	if isBinary(data) {
+		return "", fmt.Errorf("%s: %w", path, ErrBinaryFile)
+	}
+	return FormatAttachment("File "+path, string(data), truncated), nil
+}
+
+// EstimateReferenceTokens estimates the tokens an @path would add without
+// reading whole files.
+func EstimateReferenceTokens(path string) int {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	if info.IsDir() {
+		tree, _ := dirTree(path)
+		return EstimateTokens(tree)
+	}
+	size := info.Size()
+	if size > maxFileBytes {
+		size = maxFileBytes
+	}
+	return int(size) / 4
+}
+
+// EstimateTokens approximates the token count of text (~4 bytes/token).
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+func readFileCapped(path string) ([]byte, bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, false, err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxFileBytes+1))
+	if err != nil {
+		return nil, false, err
+	}
+	if len(data) > maxFileBytes {
+		return data[:maxFileBytes], true, nil
+	}
+	return data, false, nil
+}
+
+// isBinary treats data as binary if its first 8 KB has a NUL byte or is
+// not valid UTF-8.
+func isBinary(data []byte) bool {
+	sample := data
+	if len(sample) > 8*1024 {
+		sample = sample[:8*1024]
+		// Don't misjudge a multi-byte rune cut at the boundary.
+		for i := 0; i < utf8.UTFMax && !utf8.Valid(sample); i++ {
+			sample = sample[:len(sample)-1]
+		}
+	}
+	return bytes.IndexByte(sample, 0) >= 0 || !utf8.Valid(sample)
+}
+
+// dirTree lists root as an indented tree, skipping hidden entries and
+// dependency directories.
+func dirTree(root string) (string, bool) {
+	var (
+		b       strings.Builder
+		entries int
+	)
+	var walk func(dir string, depth int) bool
+	walk = func(dir string, depth int) bool {
+		list, err := os.ReadDir(dir)
+		if err != nil {
+			return true
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].Name() < list[j].Name() })
+		for _, e := range list {
+			name := e.Name()
+			if strings.HasPrefix(name, ".") || (e.IsDir() && skipDirs[name]) {
+				continue
+			}
+			if entries >= maxTreeEntries {
+				return false
+			}
+			entries++
+			b.WriteString(strings.Repeat("  ", depth) + name)
+			if e.IsDir() {
+				b.WriteString("/\n")
+				if depth+1 < maxTreeDepth && !walk(filepath.Join(dir, name), depth+1) {
+					return false
+				}
+				continue
+			}
+			b.WriteString("\n")
+		}
+		return true
+	}
+	complete := walk(root, 0)
+	return b.String(), !complete
+}
@@ -4,21 +4,48 @@ import (
 	"fmt"
 	"os"
 	"strings"
+
+	"github.com/benji/cogito/internal/config"
 )
 
-func BuildSystemMessage(includeCWD bool, customInstructions string) string {
+func BuildSystemMessage(ctx config.ContextConfig, customInstructions string) string {
 	msg := `You are Cogito, a terminal assistant. Rules:
 - Be direct — no filler, greetings, or unnecessary preamble
 - Give complete, useful answers — include full code examples and explanations when the question warrants it
 - For simple questions, keep it brief. For complex questions, give a thorough response
 - Never repeat or echo the working directory back to the user`
 
-	if includeCWD {
+	if ctx.IncludeCWD {
 		if cwd, err := os.Getwd(); err == nil {
 			msg += fmt.Sprintf("\n[Context: user is in %s — do NOT mention this unless they ask]", cwd)
 		}
 	}
 
+	if ctx.IncludeShellHistory {
+		if cmds, err := ReadShellHistory(ctx.ShellHistoryLines); err == nil && len(cmds) > 0 {
+			msg += "\n[Context: user's recent shell commands, oldest first — use only if relevant]\n"
+			for _, c := range cmds {
+				msg += "  " + strings.ReplaceAll(c, "\n", "\n    ") + "\n"
+			}
+			msg = strings.TrimRight(msg, "\n")
+		}
+	}
+
+	if git, ok := ReadGitInfo(ctx); ok {
+		if git.Branch != "" {
+			msg += fmt.Sprintf("\n[Context: git branch %s]", git.Branch)
+		}
+		if git.Status != "" {
+			msg += "\n[Context: git status — " + strings.ReplaceAll(git.Status, "\n", "\n  ") + "]"
+		}
+		if len(git.Commits) > 0 {
+			msg += "\n[Context: recent commits, newest first]\n  " + strings.Join(git.Commits, "\n  ")
+		}
+		if git.Diff != "" {
+			msg += "\n[Context: staged changes — use only if relevant]\n" + git.Diff
+		}
+	}
+
 	if strings.TrimSpace(customInstructions) != "" {
 		msg += "\n\nUser's custom instructions:\n" + customInstructions
 	}
@@ -0,0 +1,133 @@
+// Package history keeps the prompts typed into the TUI across runs, for
+// up/down recall and reverse search.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/benji/cogito/internal/redact"
+)
+
+// DefaultMaxEntries is used when no positive maximum is configured.
+const DefaultMaxEntries = 1000
+
+// History is the list of past prompts, oldest first, stored one JSON
+// string per line so multiline prompts survive.
+type History struct {
+	path     string
+	max      int
+	entries  []string
+	redactor *redact.Redactor
+}
+
+// Load reads the history file at path; a missing file is an empty
+// history. Prompts that redactor finds secrets in are never recorded.
+func Load(path string, max int, redactor *redact.Redactor) (*History, error) {
+	if max <= 0 {
+		max = DefaultMaxEntries
+	}
+	h := &History{path: path, max: max, redactor: redactor}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return h, nil
+	}
+	if err != nil {
+		return h, err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		var entry string
+		if json.Unmarshal(sc.Bytes(), &entry) == nil && entry != "" {
+			h.entries = append(h.entries, entry)
+		}
+	}
+	if len(h.entries) > h.max {
+		h.entries = h.entries[len(h.entries)-h.max:]
+	}
+	return h, sc.Err()
+}
+
+// Entries returns the prompts, oldest first.
+func (h *History) Entries() []string {
+	if h == nil {
+		return nil
+	}
+	return h.entries
+}
+
+// Len returns the number of prompts.
+func (h *History) Len() int {
+	return len(h.Entries())
+}
+
+// Add records prompt as the newest entry, dropping an older copy of it,
+// and rewrites the file. Like HISTCONTROL=ignorespace, a prompt starting
+// with a space is not recorded; neither is one containing a secret.
+func (h *History) Add(prompt string) error {
+	if h == nil || !h.keep(prompt) {
+		return nil
+	}
+	prompt = strings.TrimSpace(prompt)
+	h.entries = slices.DeleteFunc(h.entries, func(e string) bool { return e == prompt })
+	h.entries = append(h.entries, prompt)
+	if len(h.entries) > h.max {
+		h.entries = h.entries[len(h.entries)-h.max:]
+	}
+	return h.save()
+}
+
+func (h *History) keep(prompt string) bool {
+	if strings.TrimSpace(prompt) == "" || strings.HasPrefix(prompt, " ") {
+		return false
+	}
+	_, n := h.redactor.Redact(prompt)
+	return n == 0
+}
+
+// Search returns the index of the newest entry before index before that
+// contains query, or -1. Pass Len() to search from the newest entry.
+func (h *History) Search(query string, before int) int {
+	for i := min(before, h.Len()) - 1; i >= 0; i-- {
+		if strings.Contains(h.entries[i], query) {
+			return i
+		}
+	}
+	return -1
+}
+
+// save replaces the file atomically; prompts can be private, so it is
+// readable by the owner only.
+func (h *History) save() error {
+	var b strings.Builder
+	for _, e := range h.entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(h.path), ".history-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(b.String()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), h.path)
+}
@@ -0,0 +1,89 @@
+// Package oneshot answers a single query without the TUI, streaming the
+// reply to a writer. It backs "cogito -p" and positional-argument use.
+package oneshot
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/benji/cogito/internal/config"
+	shellctx "github.com/benji/cogito/internal/context"
+	"github.com/benji/cogito/internal/provider"
+	"github.com/benji/cogito/internal/redact"
+	"github.com/benji/cogito/internal/ui"
+)
+
+type Options struct {
+	// Markdown buffers the reply and renders it with glamour at Width
+	// instead of streaming the raw text.
+	Markdown bool
+	Width    int
+
+	// Notices receives status lines such as the redaction count, kept
+	// apart from the reply. Nil discards them.
+	Notices io.Writer
+}
+
+// Run sends query with the configured system prompt and writes the reply
+// to out.
+func Run(ctx context.Context, cfg config.Config, query string, out io.Writer, opts Options) error {
+	if err := cfg.CheckAPIKey(); err != nil {
+		return err
+	}
+	ui.SetAccentColor(cfg.Theme.AccentColor)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	p, err := provider.New(cfg.Provider, cfg.ProviderConfig())
+	if err != nil {
+		return err
+	}
+
+	redactor, err := cfg.Redactor()
+	if err != nil {
+		return err
+	}
+	system, n := redactor.Redact(shellctx.BuildSystemMessage(cfg.Context, cfg.CustomInstructions))
+	query, m := redactor.Redact(query)
+	if notice := redact.Notice(n + m); notice != "" && opts.Notices != nil {
+		fmt.Fprintln(opts.Notices, notice)
+	}
+
+	messages := []provider.ChatMessage{
+		{Role: provider.RoleSystem, Content: system},
+		{Role: provider.RoleUser, Content: query},
+	}
+
+	ch := make(chan string, 64)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- p.StreamChat(ctx, messages, ch)
+	}()
+
+	var full strings.Builder
+	for chunk := range ch {
+		if opts.Markdown {
+			full.WriteString(chunk)
+			continue
+		}
+		if _, err := io.WriteString(out, chunk); err != nil {
+			return err
+		}
+	}
+	if err := <-errCh; err != nil {
+		return err
+	}
+
+	if !opts.Markdown {
+		_, err := io.WriteString(out, "\n")
+		return err
+	}
+	rendered, err := ui.RenderMarkdown(full.String(), opts.Width)
+	if err != nil {
+		rendered = full.String()
+	}
+	_, err = fmt.Fprintln(out, rendered)
+	return err
+}
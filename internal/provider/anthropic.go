@@ -0,0 +1,228 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	anthropicDefaultBaseURL = "https://api.anthropic.com"
+	anthropicVersion        = "2023-06-01"
+	anthropicMaxTokens      = 4096
+)
+
+// errAnthropicTruncated is returned when the event stream ends before
+// message_stop.
+var errAnthropicTruncated = errors.New("anthropic: stream ended before the reply was complete")
+
+// AnthropicProvider talks to the Anthropic Messages API directly over
+// HTTP, streaming responses as server-sent events.
+type AnthropicProvider struct {
+	client  *http.Client
+	apiKey  string
+	model   string
+	baseURL string
+}
+
+func init() {
+	Register("anthropic", Backend{
+		RequiresKey: true,
+		New: func(cfg Config) Provider {
+			return NewAnthropic(cfg.APIKey, cfg.Model, cfg.BaseURL)
+		},
+	})
+}
+
+func NewAnthropic(apiKey, model, baseURL string) *AnthropicProvider {
+	if baseURL == "" {
+		baseURL = anthropicDefaultBaseURL
+	}
+	return &AnthropicProvider{
+		client:  http.DefaultClient,
+		apiKey:  apiKey,
+		model:   model,
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+func (p *AnthropicProvider) SetModel(model string) {
+	p.model = model
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model     string             `json:"model"`
+	MaxTokens int                `json:"max_tokens"`
+	System    string             `json:"system,omitempty"`
+	Messages  []anthropicMessage `json:"messages"`
+	Stream    bool               `json:"stream"`
+}
+
+type anthropicEvent struct {
+	Type  string `json:"type"`
+	Delta struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"delta"`
+	Error *anthropicError `json:"error"`
+}
+
+type anthropicError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+func (e *anthropicError) Error() string {
+	return fmt.Sprintf("anthropic: %s: %s", e.Type, e.Message)
+}
+
+func (p *AnthropicProvider) StreamChat(ctx context.Context, messages []ChatMessage, chunks chan<- string) error {
+	defer close(chunks)
+
+	// The Messages API takes the system prompt as a top-level field
+	// rather than as a message with role "system".
+	req := anthropicRequest{
+		Model:     p.model,
+		MaxTokens: anthropicMaxTokens,
+		Stream:    true,
+	}
+	var system []string
+	for _, m := range messages {
+		if m.Role == RoleSystem {
+			system = append(system, m.Content)
+			continue
+		}
+		req.Messages = append(req.Messages, anthropicMessage{Role: string(m.Role), Content: m.Content})
+	}
+	req.System = strings.Join(system, "\n\n")
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.do(ctx, http.MethodPost, "/v1/messages", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		data, ok := strings.CutPrefix(line, "data:")
+		if !ok {
+			continue
+		}
+
+		var ev anthropicEvent
+		if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &ev); err != nil {
+			return fmt.Errorf("anthropic: decoding stream event: %w", err)
+		}
+
+		switch ev.Type {
+		case "content_block_delta":
+			if ev.Delta.Type != "text_delta" || ev.Delta.Text == "" {
+				continue
+			}
+			select {
+			case chunks <- ev.Delta.Text:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		case "message_stop":
+			return nil
+		case "error":
+			if ev.Error != nil {
+				return ev.Error
+			}
+			return fmt.Errorf("anthropic: stream error")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	// A complete reply always ends with message_stop; without it the
+	// connection was cut and the text so far is partial.
+	return errAnthropicTruncated
+}
+
+func (p *AnthropicProvider) ListModels(ctx context.Context) ([]string, error) {
+	var models []string
+	afterID := ""
+	for {
+		path := "/v1/models?limit=100"
+		if afterID != "" {
+			path += "&after_id=" + url.QueryEscape(afterID)
+		}
+		resp, err := p.do(ctx, http.MethodGet, path, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var page struct {
+			Data []struct {
+				ID string `json:"id"`
+			} `json:"data"`
+			HasMore bool   `json:"has_more"`
+			LastID  string `json:"last_id"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, m := range page.Data {
+			models = append(models, m.ID)
+		}
+		if !page.HasMore || page.LastID == "" {
+			return models, nil
+		}
+		afterID = page.LastID
+	}
+}
+
+// do sends an authenticated request and turns non-2xx responses into errors.
+func (p *AnthropicProvider) do(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, p.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-api-key", p.apiKey)
+	req.Header.Set("anthropic-version", anthropicVersion)
+	if body != nil {
+		req.Header.Set("content-type", "application/json")
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 == 2 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	var apiErr struct {
+		Error *anthropicError `json:"error"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if json.Unmarshal(data, &apiErr) == nil && apiErr.Error != nil {
+		return nil, &statusError{code: resp.StatusCode, err: fmt.Errorf("%w (status %d)", apiErr.Error, resp.StatusCode)}
+	}
+	return nil, &statusError{code: resp.StatusCode, err: fmt.Errorf("anthropic: unexpected status %s", resp.Status)}
+}
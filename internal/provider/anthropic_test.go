@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// sseServer stands in for the Messages API, streaming events as
+// server-sent events and recording the last request body.
+func sseServer(t *testing.T, events ...string) (*httptest.Server, *anthropicRequest) {
+	t.Helper()
+	var got anthropicRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("x-api-key") != "test-key" || r.Header.Get("anthropic-version") != anthropicVersion {
+			t.Errorf("missing auth headers: %v", r.Header)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, ev := range events {
+			var typ struct {
+				Type string `json:"type"`
+			}
+			_ = json.Unmarshal([]byte(ev), &typ)
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", typ.Type, ev)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &got
+}
+
+func textDelta(s string) string {
+	return fmt.Sprintf(`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":%q}}`, s)
+}
+
+// collect runs StreamChat and returns the streamed text and its error.
+func collect(p Provider, messages []ChatMessage) (string, error) {
+	chunks := make(chan string)
+	errCh := make(chan error, 1)
+	go func() { errCh <- p.StreamChat(context.Background(), messages, chunks) }()
+	var b strings.Builder
+	for c := range chunks {
+		b.WriteString(c)
+	}
+	return b.String(), <-errCh
+}
+
+func TestAnthropicStream(t *testing.T) {
+	srv, req := sseServer(t,
+		`{"type":"message_start","message":{"id":"msg_1"}}`,
+		`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+		textDelta("Hello"),
+		`{"type":"ping"}`,
+		textDelta(", world"),
+		`{"type":"content_block_stop","index":0}`,
+		`{"type":"message_delta","delta":{"stop_reason":"end_turn"}}`,
+		`{"type":"message_stop"}`,
+	)
+	p := NewAnthropic("test-key", "claude-test", srv.URL)
+
+	text, err := collect(p, []ChatMessage{
+		{Role: RoleSystem, Content: "be brief"},
+		{Role: RoleUser, Content: "hi"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "Hello, world" {
+		t.Errorf("text = %q", text)
+	}
+	if req.System != "be brief" || len(req.Messages) != 1 || req.Messages[0].Role != "user" || !req.Stream {
+		t.Errorf("request = %+v; want the system prompt top-level and one user message", req)
+	}
+}
+
+func TestAnthropicStreamErrorEvent(t *testing.T) {
+	srv, _ := sseServer(t,
+		textDelta("partial"),
+		`{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+	)
+	text, err := collect(NewAnthropic("test-key", "claude-test", srv.URL), []ChatMessage{{Role: RoleUser, Content: "hi"}})
+
+	var apiErr *anthropicError
+	if !errors.As(err, &apiErr) || apiErr.Type != "overloaded_error" {
+		t.Errorf("err = %v, want the overloaded_error event", err)
+	}
+	if text != "partial" {
+		t.Errorf("text = %q", text)
+	}
+}
+
+func TestAnthropicStreamTruncated(t *testing.T) {
+	srv, _ := sseServer(t, textDelta("cut o"))
+	_, err := collect(NewAnthropic("test-key", "claude-test", srv.URL), []ChatMessage{{Role: RoleUser, Content: "hi"}})
+	if !errors.Is(err, errAnthropicTruncated) {
+		t.Errorf("err = %v, want errAnthropicTruncated", err)
+	}
+}
+
+func TestAnthropicErrorStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   string
+	}{
+		{"api error", http.StatusUnauthorized, `{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`, "authentication_error: invalid x-api-key (status 401)"},
+		{"no error body", http.StatusBadGateway, `<html>bad gateway</html>`, "unexpected status 502"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer srv.Close()
+
+			_, err := collect(NewAnthropic("test-key", "claude-test", srv.URL), []ChatMessage{{Role: RoleUser, Content: "hi"}})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want it to contain %q", err, tt.want)
+			}
+			if code := statusCode(err); code != tt.status {
+				t.Errorf("statusCode = %d, want %d", code, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// statusError attaches the HTTP status of a failed API call to its error.
+type statusError struct {
+	code int
+	err  error
+}
+
+func (e *statusError) Error() string { return e.err.Error() }
+func (e *statusError) Unwrap() error { return e.err }
+
+// CheckResult is the outcome of Check.
+type CheckResult struct {
+	Models      []string
+	ListErr     error
+	ListLatency time.Duration
+
+	// ChatLatency is the time to the first streamed token.
+	ChatErr     error
+	ChatLatency time.Duration
+
+	// ModelListed is false when ListModels worked but didn't include the
+	// model being checked.
+	ModelListed bool
+}
+
+// checkPrompt is the tiny request sent by Check; the stream is cut after
+// the first token anyway.
+const checkPrompt = "Reply with the single word OK."
+
+// Check exercises p the way a query would: it lists the models and
+// streams a tiny chat with model, stopping at the first token.
+func Check(ctx context.Context, p Provider, model string) CheckResult {
+	var r CheckResult
+
+	start := time.Now()
+	r.Models, r.ListErr = p.ListModels(ctx)
+	r.ListLatency = time.Since(start)
+	r.ModelListed = r.ListErr != nil || slices.Contains(r.Models, model)
+
+	chatCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	chunks := make(chan string, 16)
+	errCh := make(chan error, 1)
+	start = time.Now()
+	go func() {
+		errCh <- p.StreamChat(chatCtx, []ChatMessage{{Role: RoleUser, Content: checkPrompt}}, chunks)
+	}()
+
+	if _, ok := <-chunks; ok {
+		r.ChatLatency = time.Since(start)
+		cancel()
+		for range chunks {
+		}
+		<-errCh
+		return r
+	}
+	r.ChatLatency = time.Since(start)
+	if err := <-errCh; err != nil {
+		r.ChatErr = err
+	} else {
+		r.ChatErr = errors.New("the model returned an empty reply")
+	}
+	return r
+}
+
+// DescribeError explains a failed provider call: a bad key, an unknown
+// model, an unreachable host or a timeout, followed by the raw error.
+func DescribeError(err error) string {
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timed out — is the base URL right?"
+	case errors.Is(err, errOllamaModelMissing):
+		return fmt.Sprintf("unknown model (%v)", err)
+	case errors.As(err, &dnsErr):
+		return fmt.Sprintf("host not found (%s)", dnsErr.Name)
+	case errors.As(err, &opErr):
+		return fmt.Sprintf("host unreachable (%v)", opErr.Err)
+	}
+
+	switch code := statusCode(err); {
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		return fmt.Sprintf("authentication failed — check the API key (%v)", err)
+	case code == http.StatusNotFound:
+		return fmt.Sprintf("not found — unknown model or wrong base URL (%v)", err)
+	case code == http.StatusTooManyRequests:
+		return fmt.Sprintf("rate limited or out of quota (%v)", err)
+	case code >= 500:
+		return fmt.Sprintf("server error (%v)", err)
+	}
+	return err.Error()
+}
+
+// statusCode digs the HTTP status out of an error from any backend, or
+// returns 0.
+func statusCode(err error) int {
+	var se *statusError
+	var apiErr *openai.APIError
+	var reqErr *openai.RequestError
+	switch {
+	case errors.As(err, &se):
+		return se.code
+	case errors.As(err, &apiErr):
+		return apiErr.HTTPStatusCode
+	case errors.As(err, &reqErr):
+		return reqErr.HTTPStatusCode
+	}
+	return 0
+}
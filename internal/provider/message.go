@@ -9,6 +9,6 @@ const (
 )
 
 type ChatMessage struct {
-	Role    Role
-	Content string
+	Role    Role   `json:"role"`
+	Content string `json:"content"`
 }
@@ -0,0 +1,250 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const ollamaDefaultBaseURL = "http://localhost:11434"
+
+// errOllamaModelMissing marks a chat request that failed because the
+// model has not been pulled yet.
+var errOllamaModelMissing = errors.New("ollama: model not found")
+
+// errOllamaTruncated is returned when the stream ends before the chunk
+// marked done.
+var errOllamaTruncated = errors.New("ollama: stream ended before the reply was complete")
+
+// OllamaOptions carries the Ollama-specific knobs from config.
+type OllamaOptions struct {
+	KeepAlive    string
+	PullMissing  bool
+	Options      map[string]any
+	ModelOptions map[string]map[string]any
+}
+
+// OllamaProvider talks to Ollama's native API: /api/chat with NDJSON
+// streaming, /api/tags for local models and /api/pull for downloads.
+type OllamaProvider struct {
+	client  *http.Client
+	model   string
+	baseURL string
+	opts    OllamaOptions
+}
+
+func init() {
+	Register("ollama", Backend{
+		New: func(cfg Config) Provider {
+			return NewOllama(cfg.Model, cfg.BaseURL, cfg.Ollama)
+		},
+	})
+}
+
+func NewOllama(model, baseURL string, opts OllamaOptions) *OllamaProvider {
+	if baseURL == "" {
+		baseURL = ollamaDefaultBaseURL
+	}
+	// Accept a base URL that was set up for Ollama's OpenAI-compatible
+	// endpoint and point it back at the native API root.
+	baseURL = strings.TrimSuffix(strings.TrimRight(baseURL, "/"), "/v1")
+	return &OllamaProvider{
+		client:  http.DefaultClient,
+		model:   model,
+		baseURL: baseURL,
+		opts:    opts,
+	}
+}
+
+func (p *OllamaProvider) SetModel(model string) {
+	p.model = model
+}
+
+type ollamaChatRequest struct {
+	Model     string          `json:"model"`
+	Messages  []ollamaMessage `json:"messages"`
+	Stream    bool            `json:"stream"`
+	KeepAlive string          `json:"keep_alive,omitempty"`
+	Options   map[string]any  `json:"options,omitempty"`
+}
+
+type ollamaMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type ollamaChatChunk struct {
+	Message ollamaMessage `json:"message"`
+	Done    bool          `json:"done"`
+	Error   string        `json:"error"`
+}
+
+// modelOptions merges the global options with the per-model overrides.
+func (p *OllamaProvider) modelOptions() map[string]any {
+	perModel := p.opts.ModelOptions[p.model]
+	if len(p.opts.Options) == 0 && len(perModel) == 0 {
+		return nil
+	}
+	merged := make(map[string]any, len(p.opts.Options)+len(perModel))
+	for k, v := range p.opts.Options {
+		merged[k] = v
+	}
+	for k, v := range perModel {
+		merged[k] = v
+	}
+	return merged
+}
+
+func (p *OllamaProvider) StreamChat(ctx context.Context, messages []ChatMessage, chunks chan<- string) error {
+	defer close(chunks)
+
+	req := ollamaChatRequest{
+		Model:     p.model,
+		Stream:    true,
+		KeepAlive: p.opts.KeepAlive,
+		Options:   p.modelOptions(),
+	}
+	for _, m := range messages {
+		req.Messages = append(req.Messages, ollamaMessage{Role: string(m.Role), Content: m.Content})
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.do(ctx, http.MethodPost, "/api/chat", body)
+	if errors.Is(err, errOllamaModelMissing) && p.opts.PullMissing {
+		if err := p.PullModel(ctx, p.model); err != nil {
+			return err
+		}
+		resp, err = p.do(ctx, http.MethodPost, "/api/chat", body)
+	}
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var chunk ollamaChatChunk
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return fmt.Errorf("ollama: decoding stream: %w", err)
+		}
+		if chunk.Error != "" {
+			return fmt.Errorf("ollama: %s", chunk.Error)
+		}
+		if chunk.Message.Content != "" {
+			select {
+			case chunks <- chunk.Message.Content:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if chunk.Done {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	// The last chunk of a complete reply has "done": true; without it the
+	// connection was cut and the text so far is partial.
+	return errOllamaTruncated
+}
+
+// ListModels returns the models available locally via /api/tags.
+func (p *OllamaProvider) ListModels(ctx context.Context) ([]string, error) {
+	resp, err := p.do(ctx, http.MethodGet, "/api/tags", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var tags struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return nil, err
+	}
+	models := make([]string, 0, len(tags.Models))
+	for _, m := range tags.Models {
+		models = append(models, m.Name)
+	}
+	return models, nil
+}
+
+// PullModel downloads a model and blocks until Ollama reports success.
+func (p *OllamaProvider) PullModel(ctx context.Context, model string) error {
+	body, err := json.Marshal(map[string]any{"model": model, "stream": false})
+	if err != nil {
+		return err
+	}
+	resp, err := p.do(ctx, http.MethodPost, "/api/pull", body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return err
+	}
+	if result.Error != "" {
+		return fmt.Errorf("ollama: pulling %s: %s", model, result.Error)
+	}
+	return nil
+}
+
+// do sends a request and turns non-2xx responses into errors, using the
+// {"error": "..."} body Ollama returns.
+func (p *OllamaProvider) do(ctx context.Context, method, path string, body []byte) (*http.Response, error) {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, p.baseURL+path, r)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 == 2 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	var apiErr struct {
+		Error string `json:"error"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if json.Unmarshal(data, &apiErr) != nil || apiErr.Error == "" {
+		return nil, &statusError{code: resp.StatusCode, err: fmt.Errorf("ollama: unexpected status %s", resp.Status)}
+	}
+	if resp.StatusCode == http.StatusNotFound && strings.Contains(apiErr.Error, "not found") {
+		return nil, fmt.Errorf("%w: %s", errOllamaModelMissing, apiErr.Error)
+	}
+	return nil, &statusError{code: resp.StatusCode, err: fmt.Errorf("ollama: %s", apiErr.Error)}
+}
@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// ndjsonServer stands in for /api/chat, streaming lines as NDJSON.
+func ndjsonServer(t *testing.T, lines ...string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		for _, l := range lines {
+			fmt.Fprintln(w, l)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestOllamaStream(t *testing.T) {
+	srv := ndjsonServer(t,
+		`{"message":{"role":"assistant","content":"Hello"},"done":false}`,
+		`{"message":{"role":"assistant","content":", world"},"done":false}`,
+		`{"message":{"role":"assistant","content":""},"done":true}`,
+	)
+	text, err := collect(NewOllama("llama3", srv.URL, OllamaOptions{}), []ChatMessage{{Role: RoleUser, Content: "hi"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "Hello, world" {
+		t.Errorf("text = %q", text)
+	}
+}
+
+func TestOllamaStreamTruncated(t *testing.T) {
+	srv := ndjsonServer(t, `{"message":{"role":"assistant","content":"cut o"},"done":false}`)
+	text, err := collect(NewOllama("llama3", srv.URL, OllamaOptions{}), []ChatMessage{{Role: RoleUser, Content: "hi"}})
+	if !errors.Is(err, errOllamaTruncated) {
+		t.Errorf("err = %v, want errOllamaTruncated", err)
+	}
+	if text != "cut o" {
+		t.Errorf("text = %q", text)
+	}
+}
@@ -13,6 +13,15 @@ type OpenAIProvider struct {
 	model  string
 }
 
+func init() {
+	Register("openai", Backend{
+		RequiresKey: true,
+		New: func(cfg Config) Provider {
+			return NewOpenAI(cfg.APIKey, cfg.Model, cfg.BaseURL)
+		},
+	})
+}
+
 func NewOpenAI(apiKey, model, baseURL string) *OpenAIProvider {
 	var client *openai.Client
 	if baseURL != "" {
@@ -5,4 +5,6 @@ import "context"
 type Provider interface {
 	StreamChat(ctx context.Context, messages []ChatMessage, chunks chan<- string) error
 	ListModels(ctx context.Context) ([]string, error)
+	// SetModel switches the model used by later StreamChat calls.
+	SetModel(model string)
 }
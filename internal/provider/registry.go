@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Config is the typed configuration block handed to a backend's
+// constructor. Backends read the fields they understand and ignore the rest.
+type Config struct {
+	APIKey  string
+	Model   string
+	BaseURL string
+	Ollama  OllamaOptions
+}
+
+// Backend describes a registered provider implementation.
+type Backend struct {
+	// RequiresKey is false for backends that run without authentication.
+	RequiresKey bool
+	New         func(cfg Config) Provider
+}
+
+var backends = map[string]Backend{}
+
+// Register makes a backend available under name. It is meant to be called
+// from init functions and panics on duplicate names.
+func Register(name string, b Backend) {
+	if _, dup := backends[name]; dup {
+		panic("provider: Register called twice for " + name)
+	}
+	backends[name] = b
+}
+
+// New constructs the backend registered under name.
+func New(name string, cfg Config) (Provider, error) {
+	b, ok := backends[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q", name)
+	}
+	return b.New(cfg), nil
+}
+
+// Names returns the registered backend names in sorted order.
+func Names() []string {
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RequiresAPIKey reports whether the named backend needs an API key.
+// Unknown names are assumed to need one.
+func RequiresAPIKey(name string) bool {
+	b, ok := backends[name]
+	return !ok || b.RequiresKey
+}
@@ -0,0 +1,155 @@
+// Package redact masks secrets (API keys, credentials, private keys,
+// tokens) in text before it is sent to a model provider.
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// placeholderPrefix starts every replacement, so text that was already
+// redacted isn't counted twice.
+const placeholderPrefix = "[REDACTED"
+
+// Rule is a named pattern whose matches are replaced. When the pattern has
+// a capture group, only the first group is replaced and the rest of the
+// match (a variable name, a URL's host) is kept.
+type Rule struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+}
+
+// builtinRules cover the common credential formats.
+var builtinRules = []Rule{
+	{"private-key", `-----BEGIN [A-Z0-9 ]*PRIVATE KEY(?: BLOCK)?-----[\s\S]*?-----END [A-Z0-9 ]*PRIVATE KEY(?: BLOCK)?-----`},
+	{"aws-access-key", `\b(?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16}\b`},
+	{"aws-secret-key", `(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40,})`},
+	{"api-key", `\bsk-(?:proj-|ant-(?:api\d\d-)?)?[A-Za-z0-9_-]{20,}`},
+	{"github-token", `\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`},
+	{"slack-token", `\bxox[abprs]-[A-Za-z0-9-]{10,}`},
+	{"google-api-key", `\bAIza[0-9A-Za-z_-]{35}`},
+	{"stripe-key", `\b[rs]k_(?:live|test)_[0-9A-Za-z]{16,}`},
+	{"jwt", `\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`},
+	{"bearer-token", `(?i)\bbearer\s+([A-Za-z0-9._~+/-]{20,}=*)`},
+	{"url-password", `\b[A-Za-z][A-Za-z0-9+.-]*://[^\s:/@]+:([^\s@/]+)@`},
+	{"env-secret", `(?m)^[ \t]*(?:export[ \t]+)?[A-Z0-9_]*(?:KEY|TOKEN|SECRET|PASSWORD|PASSWD|CREDENTIALS?)[A-Z0-9_]*=["']?([A-Za-z0-9+/=_.~!@%^*-]{8,})(?:["'\s]|$)`},
+}
+
+// builtinChecks hold extra conditions a built-in rule's match must meet.
+// An env-secret value must look random: a plain word or identifier such
+// as "changeme" or "my_api_key" is left alone.
+var builtinChecks = map[string]func(string) bool{
+	"env-secret": looksSecret,
+}
+
+type rule struct {
+	name  string
+	re    *regexp.Regexp
+	check func(string) bool
+}
+
+// Redactor replaces secrets matched by its rules with placeholders.
+// A nil *Redactor leaves text unchanged.
+type Redactor struct {
+	rules []rule
+}
+
+// New builds a Redactor from the built-in rules plus custom ones. A custom
+// rule that doesn't compile is skipped and reported in the returned error;
+// the Redactor is usable either way.
+func New(custom []Rule) (*Redactor, error) {
+	r := &Redactor{}
+	var bad []string
+	for i, c := range append(append([]Rule{}, builtinRules...), custom...) {
+		re, err := regexp.Compile(c.Pattern)
+		if err != nil {
+			bad = append(bad, fmt.Sprintf("%s: %v", c.Name, err))
+			continue
+		}
+		name := c.Name
+		if name == "" {
+			name = "custom"
+		}
+		rl := rule{name: name, re: re}
+		if i < len(builtinRules) {
+			rl.check = builtinChecks[name]
+		}
+		r.rules = append(r.rules, rl)
+	}
+	if len(bad) > 0 {
+		return r, fmt.Errorf("invalid redaction rule %s", strings.Join(bad, "; "))
+	}
+	return r, nil
+}
+
+// Redact returns text with every secret replaced by "[REDACTED:<rule>]"
+// and the number of secrets replaced.
+func (r *Redactor) Redact(text string) (string, int) {
+	if r == nil || text == "" {
+		return text, 0
+	}
+	total := 0
+	for _, rl := range r.rules {
+		var n int
+		text, n = rl.apply(text)
+		total += n
+	}
+	return text, total
+}
+
+func (rl rule) apply(text string) (string, int) {
+	matches := rl.re.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return text, 0
+	}
+	var b strings.Builder
+	last, n := 0, 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if len(m) >= 4 && m[2] >= 0 {
+			start, end = m[2], m[3]
+		}
+		if start < last || strings.HasPrefix(text[start:end], placeholderPrefix) {
+			continue
+		}
+		if rl.check != nil && !rl.check(text[start:end]) {
+			continue
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(placeholderPrefix + ":" + rl.name + "]")
+		last = end
+		n++
+	}
+	b.WriteString(text[last:])
+	return b.String(), n
+}
+
+// looksSecret reports whether v mixes letters and digits, or is long and
+// mixes upper and lower case, as generated keys and passwords do.
+func looksSecret(v string) bool {
+	var lower, upper, digit bool
+	for _, r := range v {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		}
+	}
+	return digit && (lower || upper) || lower && upper && len(v) >= 16
+}
+
+// Notice describes n redactions for the status bar, or "" when n is 0.
+func Notice(n int) string {
+	switch n {
+	case 0:
+		return ""
+	case 1:
+		return "1 secret redacted"
+	default:
+		return fmt.Sprintf("%d secrets redacted", n)
+	}
+}
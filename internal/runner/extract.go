@@ -0,0 +1,99 @@
+package runner
+
+import "strings"
+
+// shellLangs are the fence info strings treated as runnable commands.
+var shellLangs = map[string]bool{
+	"bash":  true,
+	"sh":    true,
+	"shell": true,
+	"zsh":   true,
+}
+
+// Block is a fenced shell code block found in a response.
+type Block struct {
+	Command string
+	Line    int // index of the opening fence line
+}
+
+// codeBlock is a fenced code block in any language.
+type codeBlock struct {
+	Lang string
+	Code string
+	Line int // index of the opening fence line
+}
+
+// ExtractCommands returns the fenced bash/sh blocks in content, in order.
+// Unterminated fences (e.g. mid-stream) are ignored.
+func ExtractCommands(content string) []Block {
+	var blocks []Block
+	for _, b := range extractBlocks(content) {
+		if !shellLangs[b.Lang] {
+			continue
+		}
+		if cmd := stripPrompts(strings.Split(b.Code, "\n")); cmd != "" {
+			blocks = append(blocks, Block{Command: cmd, Line: b.Line})
+		}
+	}
+	return blocks
+}
+
+// extractBlocks returns the fenced code blocks in content, in order.
+// Unterminated fences are ignored.
+func extractBlocks(content string) []codeBlock {
+	var (
+		blocks []codeBlock
+		fence  string
+		lang   string
+		start  int
+		body   []string
+	)
+	for i, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if fence == "" {
+			if f := fenceMarker(trimmed); f != "" {
+				fence = f
+				lang = strings.ToLower(strings.TrimSpace(strings.TrimLeft(trimmed, f[:1])))
+				if sp := strings.IndexAny(lang, " \t{"); sp >= 0 {
+					lang = lang[:sp]
+				}
+				start = i
+				body = nil
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			blocks = append(blocks, codeBlock{Lang: lang, Code: strings.Join(body, "\n"), Line: start})
+			fence = ""
+			continue
+		}
+		body = append(body, line)
+	}
+	return blocks
+}
+
+// fenceMarker returns the run of backticks or tildes opening a code fence.
+func fenceMarker(line string) string {
+	for _, ch := range []string{"`", "~"} {
+		n := 0
+		for n < len(line) && line[n] == ch[0] {
+			n++
+		}
+		if n >= 3 {
+			return line[:n]
+		}
+	}
+	return ""
+}
+
+// stripPrompts removes leading "$ " shell prompts models like to include.
+func stripPrompts(lines []string) string {
+	out := make([]string, 0, len(lines))
+	for _, l := range lines {
+		if rest, ok := strings.CutPrefix(strings.TrimLeft(l, " \t"), "$ "); ok {
+			l = rest
+		}
+		out = append(out, l)
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
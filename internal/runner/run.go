@@ -0,0 +1,87 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// ShellName returns the base name of the shell commands run in.
+func ShellName() string {
+	if runtime.GOOS == "windows" {
+		return "cmd"
+	}
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return filepath.Base(shell)
+	}
+	return "sh"
+}
+
+// shellCommand returns the user's shell invocation for a command string.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	return exec.CommandContext(ctx, shell, "-c", command)
+}
+
+// Run executes command in the user's shell in dir, streaming combined
+// stdout/stderr to output. It closes output when done and returns the
+// exit code; err is only set when the command could not be run at all.
+func Run(ctx context.Context, command, dir string, output chan<- string) (int, error) {
+	defer close(output)
+
+	cmd := shellCommand(ctx, command)
+	cmd.Dir = dir
+	// Don't hang on background children that keep the output pipe open.
+	cmd.WaitDelay = 2 * time.Second
+	pr, pw := io.Pipe()
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+
+	if err := cmd.Start(); err != nil {
+		pw.Close()
+		return -1, err
+	}
+
+	waitErr := make(chan error, 1)
+	go func() {
+		err := cmd.Wait()
+		pw.Close()
+		waitErr <- err
+	}()
+
+	buf := make([]byte, 4096)
+	for {
+		n, rerr := pr.Read(buf)
+		if n > 0 {
+			select {
+			case output <- string(buf[:n]):
+			case <-ctx.Done():
+			}
+		}
+		if rerr != nil {
+			break
+		}
+	}
+
+	err := <-waitErr
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return 0, nil
+	case errors.As(err, &exitErr):
+		return exitErr.ExitCode(), nil
+	default:
+		return -1, err
+	}
+}
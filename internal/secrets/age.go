@@ -0,0 +1,71 @@
+package secrets
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"filippo.io/age"
+)
+
+// newAgeStore returns a file store whose contents are encrypted to the age
+// X25519 identity in identityPath. The identity is the user's own and is
+// never generated here: one kept next to the ciphertext would only hide
+// the keys from casual viewing.
+func newAgeStore(path, identityPath string) *fileStore {
+	return &fileStore{
+		name: BackendAge,
+		path: path,
+		seal: func(plain []byte) ([]byte, error) {
+			id, err := ageIdentity(identityPath)
+			if err != nil {
+				return nil, err
+			}
+			var buf bytes.Buffer
+			w, err := age.Encrypt(&buf, id.Recipient())
+			if err != nil {
+				return nil, err
+			}
+			if _, err := w.Write(plain); err != nil {
+				return nil, err
+			}
+			if err := w.Close(); err != nil {
+				return nil, err
+			}
+			return buf.Bytes(), nil
+		},
+		open: func(sealed []byte) ([]byte, error) {
+			id, err := ageIdentity(identityPath)
+			if err != nil {
+				return nil, err
+			}
+			r, err := age.Decrypt(bytes.NewReader(sealed), id)
+			if err != nil {
+				return nil, fmt.Errorf("decrypting %s: %w", path, err)
+			}
+			return io.ReadAll(r)
+		},
+	}
+}
+
+// ageIdentity reads the age identity at path.
+func ageIdentity(path string) (*age.X25519Identity, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("age identity %s not found (create one with age-keygen -o %s)", path, path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	ids, err := age.ParseIdentities(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("age identity %s: %w", path, err)
+	}
+	for _, id := range ids {
+		if x, ok := id.(*age.X25519Identity); ok {
+			return x, nil
+		}
+	}
+	return nil, fmt.Errorf("age identity %s: no X25519 key", path)
+}
@@ -0,0 +1,119 @@
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// fileStore keeps secrets as a JSON object in a file only the user can
+// read. seal and open, when set, transform the file contents on the way
+// out and in (the age backend encrypts with them).
+type fileStore struct {
+	name string
+	path string
+	seal func([]byte) ([]byte, error)
+	open func([]byte) ([]byte, error)
+}
+
+func newFileStore(path string) *fileStore {
+	return &fileStore{name: BackendFile, path: path}
+}
+
+func (s *fileStore) Name() string { return s.name }
+
+func (s *fileStore) Get(name string) (string, error) {
+	m, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	v, ok := m[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return v, nil
+}
+
+func (s *fileStore) Set(name, value string) error {
+	m, err := s.load()
+	if err != nil {
+		return err
+	}
+	m[name] = value
+	return s.save(m)
+}
+
+func (s *fileStore) Delete(name string) error {
+	m, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := m[name]; !ok {
+		return nil
+	}
+	delete(m, name)
+	return s.save(m)
+}
+
+func (s *fileStore) load() (map[string]string, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.open != nil {
+		if data, err = s.open(data); err != nil {
+			return nil, err
+		}
+	}
+	m := map[string]string{}
+	if len(data) == 0 {
+		return m, nil
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", s.path, err)
+	}
+	return m, nil
+}
+
+func (s *fileStore) save(m map[string]string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if s.seal != nil {
+		if data, err = s.seal(data); err != nil {
+			return err
+		}
+	}
+	return writePrivate(s.path, data)
+}
+
+// writePrivate replaces path with data, readable by the owner only. The
+// data goes to a temporary file first so a failed write can't lose the
+// existing secrets.
+func writePrivate(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".secrets-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,44 @@
+package secrets
+
+import (
+	"errors"
+
+	"github.com/zalando/go-keyring"
+)
+
+// keyringService is the service name entries are filed under.
+const keyringService = "cogito"
+
+// keyringStore uses Secret Service on Linux, Keychain on macOS and the
+// Credential Manager on Windows.
+type keyringStore struct{}
+
+func newKeyringStore() keyringStore { return keyringStore{} }
+
+// available probes the keyring; a missing entry means it answered.
+func (keyringStore) available() bool {
+	_, err := keyring.Get(keyringService, "probe")
+	return err == nil || errors.Is(err, keyring.ErrNotFound)
+}
+
+func (keyringStore) Name() string { return BackendKeyring }
+
+func (keyringStore) Get(name string) (string, error) {
+	v, err := keyring.Get(keyringService, name)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", ErrNotFound
+	}
+	return v, err
+}
+
+func (keyringStore) Set(name, value string) error {
+	return keyring.Set(keyringService, name, value)
+}
+
+func (keyringStore) Delete(name string) error {
+	err := keyring.Delete(keyringService, name)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil
+	}
+	return err
+}
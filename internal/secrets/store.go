@@ -0,0 +1,66 @@
+// Package secrets keeps API keys out of config.json: in the OS keyring
+// where one is available, otherwise in a 0600 file, optionally encrypted
+// with age.
+package secrets
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotFound is returned by Get when no secret is stored under a name.
+var ErrNotFound = errors.New("secret not found")
+
+// Store holds secrets by name (the provider name for API keys).
+type Store interface {
+	Get(name string) (string, error)
+	Set(name, value string) error
+	Delete(name string) error
+	// Name identifies the backend in messages ("keyring", "file", "age").
+	Name() string
+}
+
+// Backends accepted by Open.
+const (
+	BackendAuto    = "auto"
+	BackendKeyring = "keyring"
+	BackendFile    = "file"
+	BackendAge     = "age"
+)
+
+// Options locate the file-based stores.
+type Options struct {
+	// FilePath is the plaintext store used by the "file" backend.
+	FilePath string
+	// AgePath is the encrypted store and AgeIdentity the user's age
+	// identity file that decrypts it.
+	AgePath     string
+	AgeIdentity string
+}
+
+// Open returns the store for backend. "auto" (or "") uses the OS keyring
+// when it responds and falls back to the plaintext file otherwise.
+func Open(backend string, opts Options) (Store, error) {
+	switch backend {
+	case "", BackendAuto:
+		if ks := newKeyringStore(); ks.available() {
+			return ks, nil
+		}
+		return newFileStore(opts.FilePath), nil
+	case BackendKeyring:
+		ks := newKeyringStore()
+		if !ks.available() {
+			return nil, errors.New("no OS keyring available")
+		}
+		return ks, nil
+	case BackendFile:
+		return newFileStore(opts.FilePath), nil
+	case BackendAge:
+		if opts.AgeIdentity == "" {
+			return nil, errors.New("the age store needs an identity file")
+		}
+		return newAgeStore(opts.AgePath, opts.AgeIdentity), nil
+	default:
+		return nil, fmt.Errorf("unknown secret store %q (want auto, keyring, file or age)", backend)
+	}
+}
@@ -0,0 +1,191 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/benji/cogito/internal/config"
+	"github.com/benji/cogito/internal/provider"
+)
+
+// ErrNotFound is returned when a named session does not exist.
+var ErrNotFound = errors.New("session not found")
+
+// Session is a saved conversation, stored as one JSON file per session
+// under config.SessionsDir().
+type Session struct {
+	Name      string                 `json:"name"`
+	CreatedAt time.Time              `json:"created_at"`
+	UpdatedAt time.Time              `json:"updated_at"`
+	Model     string                 `json:"model"`
+	BaseURL   string                 `json:"base_url"`
+	CWD       string                 `json:"cwd"`
+	Messages  []provider.ChatMessage `json:"messages"`
+}
+
+// NewName returns a timestamp-based name for a fresh session.
+func NewName(t time.Time) string {
+	return t.Format("2006-01-02-150405")
+}
+
+// Turns returns the number of assistant replies in the session.
+func (s Session) Turns() int {
+	n := 0
+	for _, m := range s.Messages {
+		if m.Role == provider.RoleAssistant {
+			n++
+		}
+	}
+	return n
+}
+
+func validateName(name string) error {
+	if name == "" || name == "." || name == ".." {
+		return fmt.Errorf("invalid session name %q", name)
+	}
+	if strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("session name %q must not contain path separators", name)
+	}
+	return nil
+}
+
+func filePath(name string) (string, error) {
+	if err := validateName(name); err != nil {
+		return "", err
+	}
+	dir, err := config.SessionsDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// Save writes the session to disk, setting CreatedAt on first save and
+// always bumping UpdatedAt.
+func Save(s *Session) error {
+	path, err := filePath(s.Name)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if s.CreatedAt.IsZero() {
+		s.CreatedAt = now
+	}
+	s.UpdatedAt = now
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+func Load(name string) (Session, error) {
+	path, err := filePath(name)
+	if err != nil {
+		return Session{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Session{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		return Session{}, err
+	}
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return Session{}, fmt.Errorf("session %s: %w", name, err)
+	}
+	s.Name = name
+	return s, nil
+}
+
+// List returns all saved sessions, most recently updated first.
+// Files that fail to parse are skipped.
+func List() ([]Session, error) {
+	dir, err := config.SessionsDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []Session
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		s, err := Load(strings.TrimSuffix(e.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+		sessions = append(sessions, s)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].UpdatedAt.After(sessions[j].UpdatedAt)
+	})
+	return sessions, nil
+}
+
+// Latest returns the most recently updated session.
+func Latest() (Session, error) {
+	sessions, err := List()
+	if err != nil {
+		return Session{}, err
+	}
+	if len(sessions) == 0 {
+		return Session{}, ErrNotFound
+	}
+	return sessions[0], nil
+}
+
+func Rename(oldName, newName string) error {
+	oldPath, err := filePath(oldName)
+	if err != nil {
+		return err
+	}
+	newPath, err := filePath(newName)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(oldPath); os.IsNotExist(err) {
+		return fmt.Errorf("%w: %s", ErrNotFound, oldName)
+	}
+	if _, err := os.Stat(newPath); err == nil {
+		return fmt.Errorf("session %q already exists", newName)
+	}
+
+	s, err := Load(oldName)
+	if err != nil {
+		return err
+	}
+	s.Name = newName
+	if err := Save(&s); err != nil {
+		return err
+	}
+	return os.Remove(oldPath)
+}
+
+func Delete(name string) error {
+	path, err := filePath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		return err
+	}
+	return nil
+}
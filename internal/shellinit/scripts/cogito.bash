@@ -0,0 +1,75 @@
+# Cogito shell integration for bash.
+# Add to ~/.bashrc:  eval "$(cogito shell-init bash)"
+# Ctrl+G opens Cogito; picking a command loads it into the prompt line.
+# Ctrl+X F asks Cogito to fix the last failed command.
+# Only the command and its exit status are recorded: bash draws its prompt
+# on stderr, so the error output can't be captured cleanly. To include it,
+# re-run the command into cogito:  cmd 2>&1 | cogito fix --command 'cmd'
+
+# The DEBUG trap records the first command run from each prompt line.
+# history 1 has the whole line, but shows an older entry when HISTCONTROL
+# or HISTIGNORE kept the line out, so it is only used when it starts with
+# the recorded command.
+__cogito_preexec() {
+    [[ -n $__cogito_at_prompt && -z $COMP_LINE && $BASH_COMMAND != __cogito_* ]] || return 0
+    __cogito_at_prompt=
+    __cogito_cmd=$BASH_COMMAND
+}
+
+__cogito_precmd() {
+    local status=$? line
+    __cogito_at_prompt=
+    line="$(HISTTIMEFORMAT= builtin history 1 | sed 's/^ *[0-9]* *//')"
+    if [[ -n $__cogito_trap ]]; then
+        [[ -n $__cogito_cmd ]] || return $status
+        [[ $line == "$__cogito_cmd"* ]] || line=$__cogito_cmd
+        __cogito_cmd=
+    fi
+    export COGITO_LAST_STATUS=$status
+    export COGITO_LAST_COMMAND=$line
+    return $status
+}
+
+# __cogito_ready runs last in PROMPT_COMMAND, so the other prompt hooks
+# aren't taken for the user's command. Hooks added after it move it back
+# to the end, and that prompt's command goes unrecorded.
+__cogito_ready() {
+    if [[ $PROMPT_COMMAND != *";__cogito_ready" ]]; then
+        PROMPT_COMMAND="${PROMPT_COMMAND//;__cogito_ready/};__cogito_ready"
+        return
+    fi
+    __cogito_at_prompt=1
+}
+
+if [[ ";${PROMPT_COMMAND[*]};" != *";__cogito_precmd;"* ]]; then
+    PROMPT_COMMAND="__cogito_precmd${PROMPT_COMMAND:+;$PROMPT_COMMAND};__cogito_ready"
+fi
+
+# An existing DEBUG trap is left alone; the last command then comes from
+# history alone.
+if [[ -z $(trap -p DEBUG) ]]; then
+    __cogito_trap=1
+    trap '__cogito_preexec' DEBUG
+fi
+
+__cogito_run_widget() {
+    local tmp
+    tmp="$(mktemp "${TMPDIR:-/tmp}/cogito.XXXXXX")" || return
+    cogito "$@" --insert-file "$tmp" </dev/tty >/dev/tty
+    if [[ -s "$tmp" ]]; then
+        READLINE_LINE="$(<"$tmp")"
+        READLINE_POINT=${#READLINE_LINE}
+    fi
+    rm -f "$tmp"
+}
+
+__cogito_widget() {
+    __cogito_run_widget
+}
+
+__cogito_fix_widget() {
+    __cogito_run_widget fix
+}
+
+bind -x '"\C-g": __cogito_widget'
+bind -x '"\C-xf": __cogito_fix_widget'
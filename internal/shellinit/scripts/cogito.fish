@@ -0,0 +1,39 @@
+# Cogito shell integration for fish.
+# Add to ~/.config/fish/config.fish:  cogito shell-init fish | source
+# Ctrl+G opens Cogito; picking a command loads it into the prompt line.
+# Ctrl+X F asks Cogito to fix the last failed command.
+# Only the command and its exit status are recorded: fish can't redirect
+# its own stderr, so the error output isn't captured. To include it,
+# re-run the command into cogito:  cmd 2>&1 | cogito fix --command 'cmd'
+
+function __cogito_postexec --on-event fish_postexec
+    set -gx COGITO_LAST_STATUS $status
+    set -gx COGITO_LAST_COMMAND $argv[1]
+end
+
+function __cogito_run_widget
+    set -l tmp (mktemp)
+    or return
+    cogito $argv --insert-file $tmp </dev/tty >/dev/tty
+    if test -s $tmp
+        commandline -r -- (string collect <$tmp)
+        commandline -f end-of-line
+    end
+    rm -f $tmp
+    commandline -f repaint
+end
+
+function __cogito_widget
+    __cogito_run_widget
+end
+
+function __cogito_fix_widget
+    __cogito_run_widget fix
+end
+
+bind \cg __cogito_widget
+bind \cxf __cogito_fix_widget
+if bind -M insert >/dev/null 2>&1
+    bind -M insert \cg __cogito_widget
+    bind -M insert \cxf __cogito_fix_widget
+end
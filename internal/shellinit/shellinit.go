@@ -0,0 +1,43 @@
+// Package shellinit provides the shell snippets printed by
+// "cogito shell-init", which bind a key that opens Cogito and loads the
+// command picked in it into the shell's prompt line.
+package shellinit
+
+import (
+	"embed"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//go:embed scripts
+var scripts embed.FS
+
+var shells = map[string]string{
+	"bash": "scripts/cogito.bash",
+	"zsh":  "scripts/cogito.zsh",
+	"fish": "scripts/cogito.fish",
+}
+
+// Shells returns the supported shell names.
+func Shells() []string {
+	names := make([]string, 0, len(shells))
+	for name := range shells {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Script returns the integration snippet for shell.
+func Script(shell string) (string, error) {
+	path, ok := shells[shell]
+	if !ok {
+		return "", fmt.Errorf("unsupported shell %q (want %s)", shell, strings.Join(Shells(), ", "))
+	}
+	data, err := scripts.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -1,12 +1,27 @@
 package ui
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 const Version = "v0.1.0"
 
-func RenderHeader(modelName string, lastQuery string, maxWidth int) string {
+// RenderHeader builds the title shown in the top border. profile is
+// omitted when empty (the default profile).
+func RenderHeader(modelName string, profile string, lastQuery string, turns int, maxWidth int) string {
 	prefix := fmt.Sprintf("Cogito %s | %s", Version, modelName)
+	if profile != "" {
+		prefix = fmt.Sprintf("Cogito %s | %s @ %s", Version, modelName, profile)
+	}
+	if turns == 1 {
+		prefix += " | 1 turn"
+	} else if turns > 1 {
+		prefix += fmt.Sprintf(" | %d turns", turns)
+	}
 
+	// A multiline query is shown on one line.
+	lastQuery = strings.Join(strings.Fields(lastQuery), " ")
 	if lastQuery == "" {
 		return prefix
 	}
@@ -1,53 +1,90 @@
 package ui
 
 import (
-	"github.com/charmbracelet/bubbles/textinput"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
 var suggestionStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#555555"))
 
+const (
+	inputPrompt = "❯ "
+	// maxInputRows is how tall the input grows before it scrolls.
+	maxInputRows = 8
+)
+
+// InputModel is the query editor. It starts as a single line and grows
+// with its content; alt+enter or ctrl+j inserts a newline (terminals that
+// report shift+enter send one of those), and pasted text keeps its lines.
 type InputModel struct {
-	textInput  textinput.Model
+	textArea   textarea.Model
 	suggestion string // ghost text shown after cursor
 }
 
 func NewInputModel() InputModel {
-	ti := textinput.New()
-	ti.Placeholder = "Ask anything..."
-	ti.PromptStyle = InputPromptStyle
-	ti.Prompt = "❯ "
-	ti.Focus()
-	ti.CharLimit = 2000
-	return InputModel{textInput: ti}
+	ta := textarea.New()
+	ta.Placeholder = "Ask anything..."
+	ta.ShowLineNumbers = false
+	ta.SetPromptFunc(lipgloss.Width(inputPrompt), func(line int) string {
+		if line == 0 {
+			return inputPrompt
+		}
+		return strings.Repeat(" ", lipgloss.Width(inputPrompt))
+	})
+	ta.CharLimit = 0
+	ta.MaxHeight = 0
+	ta.KeyMap.InsertNewline = key.NewBinding(key.WithKeys("alt+enter", "ctrl+j"))
+	// ctrl+e opens $EDITOR instead (see app); end still moves to line end.
+	ta.KeyMap.LineEnd = key.NewBinding(key.WithKeys("end"))
+	for _, s := range []*textarea.Style{&ta.FocusedStyle, &ta.BlurredStyle} {
+		s.CursorLine = lipgloss.NewStyle()
+		s.Placeholder = DimStyle
+	}
+	ta.SetHeight(1)
+	ta.Focus()
+	return InputModel{textArea: ta}
 }
 
 func (m InputModel) Update(msg tea.Msg) (InputModel, tea.Cmd) {
+	// Give the textarea room to grow before it scrolls its view to the
+	// cursor, then shrink it back to the content.
+	m.textArea.SetHeight(maxInputRows)
 	var cmd tea.Cmd
-	m.textInput, cmd = m.textInput.Update(msg)
+	m.textArea, cmd = m.textArea.Update(msg)
+	m.fitHeight()
 	// Clear suggestion on any keypress (tab handling is in app.go)
 	m.suggestion = ""
 	return m, cmd
 }
 
 func (m InputModel) View() string {
-	m.textInput.PromptStyle = InputPromptStyle
-	view := m.textInput.View()
-	if m.suggestion != "" {
-		view += suggestionStyle.Render(m.suggestion)
+	m.textArea.FocusedStyle.Prompt = InputPromptStyle
+	m.textArea.BlurredStyle.Prompt = InputPromptStyle
+	view := m.textArea.View()
+	if m.suggestion != "" && !strings.Contains(view, "\n") {
+		// The view is padded to the full width; cut it after the cursor
+		// so the suggestion follows the text.
+		w := lipgloss.Width(inputPrompt) + lipgloss.Width(m.textArea.Value()) + 1
+		if w+lipgloss.Width(m.suggestion) <= m.textArea.Width()+lipgloss.Width(inputPrompt) {
+			view = ansi.Truncate(view, w, "") + suggestionStyle.Render(m.suggestion)
+		}
 	}
 	return view
 }
 
 func (m InputModel) Value() string {
-	return m.textInput.Value()
+	return m.textArea.Value()
 }
 
+// SetValue replaces the text and moves the cursor to the end.
 func (m *InputModel) SetValue(s string) {
-	m.textInput.SetValue(s)
-	// Move cursor to end of the new value
-	m.textInput.SetCursor(len(s))
+	m.textArea.SetValue(s)
+	m.fitHeight()
 }
 
 func (m *InputModel) SetSuggestion(s string) {
@@ -55,13 +92,37 @@ func (m *InputModel) SetSuggestion(s string) {
 }
 
 func (m *InputModel) Focus() tea.Cmd {
-	return m.textInput.Focus()
+	return m.textArea.Focus()
 }
 
 func (m *InputModel) Blur() {
-	m.textInput.Blur()
+	m.textArea.Blur()
 }
 
 func (m *InputModel) SetWidth(w int) {
-	m.textInput.Width = w
+	m.textArea.SetWidth(w)
+	m.fitHeight()
+}
+
+// fitHeight sizes the input to its wrapped content, up to maxInputRows.
+func (m *InputModel) fitHeight() {
+	width := max(m.textArea.Width(), 1)
+	rows := 0
+	for _, line := range strings.Split(m.textArea.Value(), "\n") {
+		rows += max(1, (lipgloss.Width(line)+width)/width)
+	}
+	rows = min(rows, maxInputRows)
+	if rows != m.textArea.Height() {
+		m.textArea.SetHeight(rows)
+	}
+}
+
+// Line returns the line the cursor is on.
+func (m InputModel) Line() int {
+	return m.textArea.Line()
+}
+
+// LineCount returns the number of lines in the input.
+func (m InputModel) LineCount() int {
+	return m.textArea.LineCount()
 }
@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/ansi"
+	"github.com/charmbracelet/glamour/styles"
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+// markdownRenderer wraps a glamour renderer and rebuilds it when the
+// content width or accent color changes.
+type markdownRenderer struct {
+	tr     *glamour.TermRenderer
+	width  int
+	accent string
+}
+
+func (r *markdownRenderer) render(content string, width int) (string, error) {
+	accent := string(AccentColor)
+	if r.tr == nil || r.width != width || r.accent != accent {
+		tr, err := glamour.NewTermRenderer(
+			glamour.WithStyles(markdownStyle(accent)),
+			glamour.WithWordWrap(width),
+		)
+		if err != nil {
+			return "", err
+		}
+		r.tr, r.width, r.accent = tr, width, accent
+	}
+
+	out, err := r.tr.Render(content)
+	if err != nil {
+		return "", err
+	}
+	return trimBlankLines(out), nil
+}
+
+// RenderMarkdown renders content once at the given width using the same
+// style as the response box.
+func RenderMarkdown(content string, width int) (string, error) {
+	var r markdownRenderer
+	return r.render(content, width)
+}
+
+// trimBlankLines drops leading and trailing lines that are visually empty
+// (glamour pads them with spaces and reset sequences).
+func trimBlankLines(s string) string {
+	lines := strings.Split(s, "\n")
+	blank := func(line string) bool {
+		return strings.TrimSpace(xansi.Strip(line)) == ""
+	}
+	for len(lines) > 0 && blank(lines[0]) {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && blank(lines[len(lines)-1]) {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// markdownStyle derives a glamour style from the dark theme, with headings
+// and links in the accent color and no document margin (the box already
+// provides padding).
+func markdownStyle(accent string) ansi.StyleConfig {
+	s := styles.DarkStyleConfig
+	margin := uint(0)
+	s.Document.Margin = &margin
+	s.Document.BlockPrefix = ""
+	s.Document.BlockSuffix = ""
+	s.Heading.Color = &accent
+	s.H1.BackgroundColor = &accent
+	s.Link.Color = &accent
+	s.LinkText.Color = &accent
+	return s
+}
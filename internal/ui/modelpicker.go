@@ -0,0 +1,214 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pickerRows is how many models the picker shows at once.
+const pickerRows = 10
+
+// ModelPickedMsg is sent when a model is chosen. Persist asks for it to be
+// saved as the default model as well.
+type ModelPickedMsg struct {
+	Model   string
+	Persist bool
+}
+
+// ModelPickerModel is a fuzzy-filtered list of model names.
+type ModelPickerModel struct {
+	filter  textinput.Model
+	models  []string
+	matches []string
+	cursor  int
+	offset  int
+	current string
+	loading bool
+	note    string
+}
+
+// NewModelPickerModel returns a picker in the loading state; SetModels
+// fills it in. current is marked in the list.
+func NewModelPickerModel(current string) ModelPickerModel {
+	ti := textinput.New()
+	ti.Placeholder = "type to filter"
+	ti.Prompt = "/ "
+	ti.PromptStyle = InputPromptStyle
+	ti.CharLimit = 100
+	ti.Width = 50
+	ti.Focus()
+	return ModelPickerModel{filter: ti, current: current, loading: true}
+}
+
+// SetModels fills the list. note explains where the names came from when
+// it isn't the provider (e.g. the fallback after an error).
+func (m *ModelPickerModel) SetModels(models []string, note string) {
+	m.models = models
+	m.note = note
+	m.loading = false
+	m.refilter()
+	for i, name := range m.matches {
+		if name == m.current {
+			m.cursor = i
+			m.scrollToCursor()
+			break
+		}
+	}
+}
+
+func (m *ModelPickerModel) SetWidth(w int) {
+	m.filter.Width = w - 4
+}
+
+func (m ModelPickerModel) Update(msg tea.Msg) (ModelPickerModel, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "up", "ctrl+p":
+			if m.cursor > 0 {
+				m.cursor--
+				m.scrollToCursor()
+			}
+			return m, nil
+		case "down", "ctrl+n":
+			if m.cursor < len(m.matches)-1 {
+				m.cursor++
+				m.scrollToCursor()
+			}
+			return m, nil
+		case "enter", "ctrl+s":
+			name := m.selected()
+			if name == "" {
+				return m, nil
+			}
+			persist := msg.String() == "ctrl+s"
+			return m, func() tea.Msg { return ModelPickedMsg{Model: name, Persist: persist} }
+		}
+	}
+
+	before := m.filter.Value()
+	var cmd tea.Cmd
+	m.filter, cmd = m.filter.Update(msg)
+	if m.filter.Value() != before {
+		m.refilter()
+	}
+	return m, cmd
+}
+
+// selected returns the highlighted model, or the typed text when nothing
+// matches so unlisted models can still be picked.
+func (m ModelPickerModel) selected() string {
+	if m.cursor < len(m.matches) {
+		return m.matches[m.cursor]
+	}
+	return strings.TrimSpace(m.filter.Value())
+}
+
+func (m *ModelPickerModel) refilter() {
+	m.matches = FuzzyFilter(m.filter.Value(), m.models)
+	m.cursor = 0
+	m.offset = 0
+}
+
+func (m *ModelPickerModel) scrollToCursor() {
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+pickerRows {
+		m.offset = m.cursor - pickerRows + 1
+	}
+}
+
+func (m ModelPickerModel) View() string {
+	var b strings.Builder
+	b.WriteString(TitleStyle.Render("Model") + "\n\n")
+	b.WriteString(m.filter.View() + "\n\n")
+
+	switch {
+	case m.loading:
+		b.WriteString(DimStyle.Render("  loading models...") + "\n")
+	case len(m.matches) == 0:
+		if q := strings.TrimSpace(m.filter.Value()); q != "" {
+			b.WriteString(DimStyle.Render("  no match — enter uses "+q+" as typed") + "\n")
+		} else {
+			b.WriteString(DimStyle.Render("  no models listed") + "\n")
+		}
+	default:
+		end := min(m.offset+pickerRows, len(m.matches))
+		for i := m.offset; i < end; i++ {
+			name := m.matches[i]
+			label := name
+			if name == m.current {
+				label += " (current)"
+			}
+			if i == m.cursor {
+				b.WriteString(SelectedStyle.Render("  ▸ "+label) + "\n")
+			} else {
+				b.WriteString(DimStyle.Render("    "+label) + "\n")
+			}
+		}
+		if len(m.matches) > pickerRows {
+			b.WriteString(DimStyle.Render(fmt.Sprintf("    %d-%d of %d", m.offset+1, end, len(m.matches))) + "\n")
+		}
+	}
+
+	if m.note != "" {
+		b.WriteString("\n" + DimStyle.Render(m.note) + "\n")
+	}
+	b.WriteString("\n" + DimStyle.Render("↑↓ navigate • enter use • ctrl+s use and save as default • esc back"))
+	return b.String()
+}
+
+// FuzzyFilter returns the items containing the characters of pattern in
+// order, best matches first. Consecutive characters and matches at the
+// start of a word score higher. An empty pattern keeps every item.
+func FuzzyFilter(pattern string, items []string) []string {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if pattern == "" {
+		return append([]string(nil), items...)
+	}
+
+	type scored struct {
+		item  string
+		score int
+	}
+	var hits []scored
+	for _, item := range items {
+		if s, ok := fuzzyScore(pattern, strings.ToLower(item)); ok {
+			hits = append(hits, scored{item, s})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].score > hits[j].score })
+
+	out := make([]string, len(hits))
+	for i, h := range hits {
+		out[i] = h.item
+	}
+	return out
+}
+
+func fuzzyScore(pattern, s string) (int, bool) {
+	score, pi, prev := 0, 0, -2
+	for si := 0; si < len(s) && pi < len(pattern); si++ {
+		if s[si] != pattern[pi] {
+			continue
+		}
+		score++
+		if si == prev+1 {
+			score += 3
+		}
+		if si == 0 || strings.ContainsRune("-_.:/ ", rune(s[si-1])) {
+			score += 2
+		}
+		prev = si
+		pi++
+	}
+	if pi < len(pattern) {
+		return 0, false
+	}
+	// Prefer shorter names among equal matches.
+	return score*100 - len(s), true
+}
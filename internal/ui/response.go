@@ -3,11 +3,17 @@ package ui
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/viewport"
 	"github.com/charmbracelet/lipgloss"
+
+	"github.com/benji/cogito/internal/runner"
 )
 
+// renderThrottle bounds how often Markdown is re-rendered while streaming.
+const renderThrottle = 80 * time.Millisecond
+
 type ResponseModel struct {
 	viewport viewport.Model
 	content  string
@@ -15,10 +21,92 @@ type ResponseModel struct {
 	height   int
 	maxLines int
 	ready    bool
+
+	// rendered is content rendered as Markdown; empty when markdown is
+	// off, the content is plain text, or rendering failed.
+	rendered   string
+	markdown   bool
+	plain      bool // current content is plain text (help, listings)
+	renderer   *markdownRenderer
+	lastRender time.Time
+
+	// commands are the runnable shell blocks found when the response was
+	// finalized; they are numbered in the view.
+	commands []runner.Block
+
+	// search is the pager search; matches are its occurrences in the
+	// displayed content and current indexes the one last jumped to.
+	search     string
+	searchBack bool
+	matches    []searchMatch
+	current    int
 }
 
 func NewResponseModel() ResponseModel {
-	return ResponseModel{}
+	return ResponseModel{renderer: &markdownRenderer{}}
+}
+
+// SetMarkdown toggles Markdown rendering; when off, the raw model text is shown.
+func (m *ResponseModel) SetMarkdown(enabled bool) {
+	m.markdown = enabled
+	m.render()
+}
+
+// render refreshes the rendered Markdown and the viewport content.
+func (m *ResponseModel) render() {
+	m.rendered = ""
+	if m.markdown && !m.plain && m.content != "" && m.width > 0 {
+		if out, err := m.renderer.render(m.source(), m.width); err == nil {
+			m.rendered = out
+		}
+		m.lastRender = time.Now()
+	}
+	if m.ready {
+		text := m.display()
+		m.findMatches(text)
+		m.viewport.SetContent(m.highlight(text))
+	}
+}
+
+// display returns the text to show: rendered Markdown if available,
+// raw content otherwise.
+func (m ResponseModel) display() string {
+	if m.rendered != "" {
+		return m.rendered
+	}
+	return m.source()
+}
+
+// source returns the content with a "[N]" label above each runnable
+// command block once the response is final.
+func (m ResponseModel) source() string {
+	if len(m.commands) == 0 {
+		return m.content
+	}
+	lines := strings.Split(m.content, "\n")
+	out := make([]string, 0, len(lines)+2*len(m.commands))
+	next := 0
+	for i, line := range lines {
+		if next < len(m.commands) && m.commands[next].Line == i {
+			label := fmt.Sprintf("[%d]", next+1)
+			if m.markdown {
+				label = "**" + label + "**"
+			}
+			out = append(out, "", label)
+			next++
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
+
+// Commands returns the numbered shell commands of the finalized response.
+func (m ResponseModel) Commands() []string {
+	cmds := make([]string, len(m.commands))
+	for i, b := range m.commands {
+		cmds[i] = b.Command
+	}
+	return cmds
 }
 
 func (m *ResponseModel) SetSize(width, height int) {
@@ -32,20 +120,42 @@ func (m *ResponseModel) SetSize(width, height int) {
 		m.viewport.Width = width
 		m.viewport.Height = height
 	}
-	m.viewport.SetContent(m.content)
+	m.render()
 }
 
 func (m *ResponseModel) AppendContent(chunk string) {
 	m.content += chunk
-	if m.ready {
-		m.viewport.SetContent(m.content)
+	if m.markdown && !m.plain && time.Since(m.lastRender) < renderThrottle {
+		return
 	}
+	m.render()
 }
 
-func (m *ResponseModel) Finalize() {}
+// SetPlain replaces the content with text that is never rendered as Markdown.
+func (m *ResponseModel) SetPlain(text string) {
+	m.Clear()
+	m.plain = true
+	m.content = text
+	m.render()
+}
+
+// Finalize renders the complete response, catching up on any chunks
+// skipped by the streaming throttle.
+func (m *ResponseModel) Finalize() {
+	if !m.plain {
+		m.commands = runner.ExtractCommands(m.content)
+	}
+	m.render()
+}
 
 func (m *ResponseModel) Clear() {
 	m.content = ""
+	m.rendered = ""
+	m.plain = false
+	m.lastRender = time.Time{}
+	m.commands = nil
+	m.search = ""
+	m.matches = nil
 	if m.ready {
 		m.viewport.SetContent("")
 		m.viewport.GotoTop()
@@ -59,14 +169,14 @@ func (m ResponseModel) Content() string {
 // View returns the raw content (compact, no fixed-height padding).
 // Use this for normal display where the box should fit the content.
 func (m ResponseModel) View() string {
-	return m.content
+	return m.display()
 }
 
 // PagerView returns the viewport view (fixed height, scrollable).
 // Use this only when in pager mode.
 func (m ResponseModel) PagerView() string {
 	if !m.ready {
-		return m.content
+		return m.display()
 	}
 	return m.viewport.View()
 }
@@ -86,12 +196,13 @@ func (m ResponseModel) maxCompactLines() int {
 	return DefaultMaxCompactLines
 }
 
-// ContentLineCount returns the number of lines in the content.
+// ContentLineCount returns the number of lines in the displayed content.
 func (m ResponseModel) ContentLineCount() int {
-	if m.content == "" {
+	text := m.display()
+	if text == "" {
 		return 0
 	}
-	return strings.Count(m.content, "\n") + 1
+	return strings.Count(text, "\n") + 1
 }
 
 // Overflows returns true if the content exceeds the max compact lines.
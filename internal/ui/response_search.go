@@ -0,0 +1,166 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// searchMatch is an occurrence of the pager search in the displayed
+// content: a line index and a span of terminal cells.
+type searchMatch struct {
+	line, start, end int
+}
+
+// Search highlights query in the pager and jumps to the first match at or
+// below the top of the view, or with backward the last match above it.
+// The search is case-insensitive unless query has an upper-case letter.
+func (m *ResponseModel) Search(query string, backward bool) {
+	m.search = query
+	m.searchBack = backward
+	m.current = -1
+	m.render()
+	if len(m.matches) == 0 {
+		return
+	}
+
+	top := m.viewport.YOffset
+	if backward {
+		m.current = len(m.matches) - 1
+		for i := len(m.matches) - 1; i >= 0; i-- {
+			if m.matches[i].line < top {
+				m.current = i
+				break
+			}
+		}
+	} else {
+		m.current = 0
+		for i, mt := range m.matches {
+			if mt.line >= top {
+				m.current = i
+				break
+			}
+		}
+	}
+	m.showCurrent()
+}
+
+// NextMatch moves to the next match in the search direction, or the
+// opposite one with reverse (n and N), wrapping around.
+func (m *ResponseModel) NextMatch(reverse bool) {
+	if len(m.matches) == 0 {
+		return
+	}
+	step := 1
+	if m.searchBack != reverse {
+		step = -1
+	}
+	m.current = (m.current + step + len(m.matches)) % len(m.matches)
+	m.showCurrent()
+}
+
+// ClearSearch removes the search and its highlights.
+func (m *ResponseModel) ClearSearch() {
+	if m.search == "" {
+		return
+	}
+	m.search = ""
+	m.matches = nil
+	m.current = -1
+	m.render()
+}
+
+// SearchQuery returns the active search, or "".
+func (m ResponseModel) SearchQuery() string {
+	return m.search
+}
+
+// SearchStatus describes the search for the pager prompt, e.g. "3/12".
+func (m ResponseModel) SearchStatus() string {
+	switch {
+	case m.search == "":
+		return ""
+	case len(m.matches) == 0:
+		return "no matches for " + m.search
+	}
+	return fmt.Sprintf("%d/%d", m.current+1, len(m.matches))
+}
+
+// showCurrent re-highlights the current match and scrolls it into view.
+func (m *ResponseModel) showCurrent() {
+	if !m.ready {
+		return
+	}
+	m.viewport.SetContent(m.highlight(m.display()))
+	line := m.matches[m.current].line
+	if line < m.viewport.YOffset || line >= m.viewport.YOffset+m.viewport.Height {
+		m.viewport.SetYOffset(line)
+	}
+}
+
+// findMatches locates the search in each line of text, ignoring styling.
+func (m *ResponseModel) findMatches(text string) {
+	m.matches = nil
+	if m.search == "" {
+		return
+	}
+	query := m.search
+	fold := !strings.ContainsFunc(query, unicode.IsUpper)
+	if fold {
+		query = strings.ToLower(query)
+	}
+	for i, line := range strings.Split(text, "\n") {
+		plain := ansi.Strip(line)
+		if fold {
+			plain = strings.ToLower(plain)
+		}
+		for off := 0; ; {
+			idx := strings.Index(plain[off:], query)
+			if idx < 0 {
+				break
+			}
+			start := ansi.StringWidth(plain[:off+idx])
+			end := start + ansi.StringWidth(query)
+			m.matches = append(m.matches, searchMatch{line: i, start: start, end: end})
+			off += idx + len(query)
+		}
+	}
+	if m.current >= len(m.matches) {
+		m.current = len(m.matches) - 1
+	}
+}
+
+// highlight marks the matches in text, the current one in the accent
+// color.
+func (m ResponseModel) highlight(text string) string {
+	if len(m.matches) == 0 {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i := len(m.matches) - 1; i >= 0; {
+		// Rebuild each line once, from all of its matches.
+		j := i
+		for j > 0 && m.matches[j-1].line == m.matches[i].line {
+			j--
+		}
+		line := lines[m.matches[i].line]
+		var b strings.Builder
+		prev := 0
+		for k := j; k <= i; k++ {
+			mt := m.matches[k]
+			style := SearchMatchStyle
+			if k == m.current {
+				style = SearchCurrentStyle
+			}
+			b.WriteString(ansi.Cut(line, prev, mt.start))
+			b.WriteString(style.Render(ansi.Strip(ansi.Cut(line, mt.start, mt.end))))
+			prev = mt.end
+		}
+		b.WriteString(ansi.TruncateLeft(line, prev, ""))
+		lines[m.matches[i].line] = b.String()
+		i = j - 1
+	}
+	return strings.Join(lines, "\n")
+}
@@ -2,158 +2,215 @@ package ui
 
 import (
 	"fmt"
-	"strconv"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/benji/cogito/internal/provider"
 )
 
+// SettingsValues holds every field the settings screen edits. It seeds
+// NewSettingsModel and is returned in SettingsSavedMsg.
+type SettingsValues struct {
+	Provider            string
+	APIKey              string
+	BaseURL             string
+	DefaultModel        string
+	ClearScreen         bool
+	Position            string
+	AccentColor         string
+	CustomInstructions  string
+	IncludeCWD          bool
+	IncludeShellHistory bool
+	ShellHistoryLines   int
+	IncludeGitBranch    bool
+	IncludeGitStatus    bool
+	IncludeGitLog       bool
+	IncludeGitDiff      bool
+	MaxResponseLines    int
+	RawOutput           bool
+}
+
 type SettingsSavedMsg struct {
-	APIKey             string
-	BaseURL            string
-	DefaultModel       string
-	ClearScreen        bool
-	Position           string
-	AccentColor        string
-	CustomInstructions string
-	IncludeCWD         bool
-	MaxResponseLines   int
+	SettingsValues
+}
+
+// ConnectionTestMsg asks for the API settings currently in the form to be
+// tried against the provider.
+type ConnectionTestMsg struct {
+	SettingsValues
 }
 
-// settingsItem is either a group header, a text input field, or a save button.
+// ConnectionResultMsg reports a connection test back to the settings
+// screen. The error strings are empty when that step worked.
+type ConnectionResultMsg struct {
+	Model       string
+	Models      []string
+	ListLatency time.Duration
+	ListErr     string
+	ChatLatency time.Duration
+	ChatErr     string
+	// ModelMissing is set when the list came back without Model in it.
+	ModelMissing bool
+}
+
+// SettingsPickModelMsg asks for the model picker over Models; the choice
+// goes into the Default Model field via SetModel.
+type SettingsPickModelMsg struct {
+	Models  []string
+	Current string
+}
+
+// settingsItem is either a group header, an editable field, or a button.
 type settingsItem struct {
 	label     string
 	hint      string // optional hint shown below the label
-	isGroup   bool   // true = collapsible header, not an input
+	isGroup   bool   // true = collapsible header, not a field
 	isSaveBtn bool   // true = save button
-	groupID   string // which group this input belongs to ("api", "prompt", "display")
-	inputIdx  int    // index into SettingsModel.inputs, -1 for group headers/buttons
+	isTestBtn bool   // true = test connection button
+	groupID   string // which group this field belongs to ("api", "prompt", "display")
+	fieldIdx  int    // index into SettingsModel.fields, -1 for group headers/buttons
 }
 
 type SettingsModel struct {
-	inputs   []textinput.Model
+	fields   []settingsField
 	items    []settingsItem
 	cursor   int
 	width    int
 	expanded map[string]bool // which groups are expanded
+	// saveErr is shown at the save button when invalid fields block saving.
+	saveErr string
+	// testing is set while a connection test runs; testResult holds the
+	// last one.
+	testing    bool
+	testResult *ConnectionResultMsg
 }
 
 const (
-	inputAPIKey = iota
-	inputBaseURL
-	inputModel
-	inputCustomInstructions
-	inputIncludeCWD
-	inputMaxResponseLines
-	inputClearScreen
-	inputPosition
-	inputAccentColor
-	inputCount
+	fieldProvider = iota
+	fieldAPIKey
+	fieldBaseURL
+	fieldModel
+	fieldCustomInstructions
+	fieldIncludeCWD
+	fieldIncludeHistory
+	fieldHistoryLines
+	fieldGitBranch
+	fieldGitStatus
+	fieldGitLog
+	fieldGitDiff
+	fieldMaxResponseLines
+	fieldClearScreen
+	fieldPosition
+	fieldAccentColor
+	fieldRawOutput
+	fieldCount
 )
 
-func NewSettingsModel(apiKey, baseURL, defaultModel, customInstructions string, includeCWD, clearScreen bool, position, accentColor string, maxResponseLines int) SettingsModel {
-	inputs := make([]textinput.Model, inputCount)
-
-	inputs[inputAPIKey] = textinput.New()
-	inputs[inputAPIKey].Placeholder = "sk-..."
-	inputs[inputAPIKey].EchoMode = textinput.EchoPassword
-	inputs[inputAPIKey].SetValue(apiKey)
-	inputs[inputAPIKey].CharLimit = 256
-	inputs[inputAPIKey].Width = 50
-
-	inputs[inputBaseURL] = textinput.New()
-	inputs[inputBaseURL].Placeholder = "leave empty for default OpenAI"
-	inputs[inputBaseURL].SetValue(baseURL)
-	inputs[inputBaseURL].CharLimit = 256
-	inputs[inputBaseURL].Width = 50
-
-	inputs[inputModel] = textinput.New()
-	inputs[inputModel].Placeholder = "gpt-4o-mini"
-	inputs[inputModel].SetValue(defaultModel)
-	inputs[inputModel].CharLimit = 100
-	inputs[inputModel].Width = 50
-
-	inputs[inputCustomInstructions] = textinput.New()
-	inputs[inputCustomInstructions].Placeholder = "e.g. Always respond in Python, be extra brief..."
-	inputs[inputCustomInstructions].SetValue(customInstructions)
-	inputs[inputCustomInstructions].CharLimit = 500
-	inputs[inputCustomInstructions].Width = 50
-
-	cwdVal := "no"
-	if includeCWD {
-		cwdVal = "yes"
+func NewSettingsModel(providers []string, v SettingsValues) SettingsModel {
+	if v.Provider == "" {
+		v.Provider = "openai"
+	}
+	if !slices.Contains(providers, v.Provider) {
+		providers = append(slices.Clone(providers), v.Provider)
 	}
-	inputs[inputIncludeCWD] = textinput.New()
-	inputs[inputIncludeCWD].Placeholder = "yes/no"
-	inputs[inputIncludeCWD].SetValue(cwdVal)
-	inputs[inputIncludeCWD].CharLimit = 3
-	inputs[inputIncludeCWD].Width = 50
-
-	clearVal := "no"
-	if clearScreen {
-		clearVal = "yes"
+	if v.ShellHistoryLines <= 0 {
+		v.ShellHistoryLines = 10
 	}
-	inputs[inputClearScreen] = textinput.New()
-	inputs[inputClearScreen].Placeholder = "yes/no"
-	inputs[inputClearScreen].SetValue(clearVal)
-	inputs[inputClearScreen].CharLimit = 3
-	inputs[inputClearScreen].Width = 50
-
-	if position == "" {
-		position = "bottom"
+	if v.Position == "" {
+		v.Position = "bottom"
 	}
-	inputs[inputPosition] = textinput.New()
-	inputs[inputPosition].Placeholder = "top/bottom"
-	inputs[inputPosition].SetValue(position)
-	inputs[inputPosition].CharLimit = 6
-	inputs[inputPosition].Width = 50
-
-	if accentColor == "" {
-		accentColor = "#FF6F61"
+	if v.AccentColor == "" {
+		v.AccentColor = "#FF6F61"
 	}
-	inputs[inputAccentColor] = textinput.New()
-	inputs[inputAccentColor].Placeholder = "#FF6F61"
-	inputs[inputAccentColor].SetValue(accentColor)
-	inputs[inputAccentColor].CharLimit = 7
-	inputs[inputAccentColor].Width = 50
-
-	if maxResponseLines <= 0 {
-		maxResponseLines = 8
+	if v.MaxResponseLines <= 0 {
+		v.MaxResponseLines = 8
 	}
-	inputs[inputMaxResponseLines] = textinput.New()
-	inputs[inputMaxResponseLines].Placeholder = "8"
-	inputs[inputMaxResponseLines].SetValue(fmt.Sprintf("%d", maxResponseLines))
-	inputs[inputMaxResponseLines].CharLimit = 3
-	inputs[inputMaxResponseLines].Width = 50
+
+	apiKey := newTextField(v.APIKey, "sk-...", 256, nil)
+	apiKey.input.EchoMode = textinput.EchoPassword
+
+	fields := make([]settingsField, fieldCount)
+	fields[fieldProvider] = newSelectField(providers, v.Provider)
+	fields[fieldAPIKey] = apiKey
+	fields[fieldBaseURL] = newTextField(v.BaseURL, "leave empty for the provider default", 256, validateBaseURL)
+	fields[fieldModel] = newTextField(v.DefaultModel, "gpt-4o-mini", 100, func(s string) error {
+		if s == "" {
+			return fmt.Errorf("pick a model (try /model for a list)")
+		}
+		return nil
+	})
+	fields[fieldCustomInstructions] = newTextAreaField(v.CustomInstructions, "e.g. Always respond in Python, be extra brief...", 2000)
+	fields[fieldIncludeCWD] = &toggleField{on: v.IncludeCWD}
+	fields[fieldIncludeHistory] = &toggleField{on: v.IncludeShellHistory}
+	fields[fieldHistoryLines] = &stepperField{value: v.ShellHistoryLines, min: 1, max: 100}
+	fields[fieldGitBranch] = &toggleField{on: v.IncludeGitBranch}
+	fields[fieldGitStatus] = &toggleField{on: v.IncludeGitStatus}
+	fields[fieldGitLog] = &toggleField{on: v.IncludeGitLog}
+	fields[fieldGitDiff] = &toggleField{on: v.IncludeGitDiff}
+	fields[fieldMaxResponseLines] = &stepperField{value: v.MaxResponseLines, min: 3, max: 100}
+	fields[fieldClearScreen] = &toggleField{on: v.ClearScreen}
+	fields[fieldPosition] = newSelectField([]string{"top", "bottom"}, v.Position)
+	fields[fieldAccentColor] = newColorField(v.AccentColor)
+	fields[fieldRawOutput] = &toggleField{on: v.RawOutput}
 
 	items := []settingsItem{
-		{label: "API Configuration", isGroup: true, groupID: "api", inputIdx: -1},
-		{label: "API Key", groupID: "api", inputIdx: inputAPIKey},
-		{label: "Base URL (Groq, OpenRouter, Ollama...)", groupID: "api", inputIdx: inputBaseURL},
-		{label: "Default Model", groupID: "api", inputIdx: inputModel},
-
-		{label: "Prompt & Context", isGroup: true, groupID: "prompt", inputIdx: -1},
-		{label: "Custom Instructions", groupID: "prompt", inputIdx: inputCustomInstructions},
-		{label: "Send Directory Context (yes/no)", hint: "Sends your current working directory to the model for relevant answers", groupID: "prompt", inputIdx: inputIncludeCWD},
-
-		{label: "Display", isGroup: true, groupID: "display", inputIdx: -1},
-		{label: "Max Response Lines", hint: "Lines shown before pager activates (default: 8)", groupID: "display", inputIdx: inputMaxResponseLines},
-		{label: "Clear Screen (yes/no)", groupID: "display", inputIdx: inputClearScreen},
-		{label: "Position (top/bottom)", groupID: "display", inputIdx: inputPosition},
-		{label: "Accent Color (hex)", groupID: "display", inputIdx: inputAccentColor},
-
-		{label: "Save & Exit", isSaveBtn: true, inputIdx: -1},
+		{label: "API Configuration", isGroup: true, groupID: "api", fieldIdx: -1},
+		{label: "Provider", hint: providerHint(providers), groupID: "api", fieldIdx: fieldProvider},
+		{label: "API Key", groupID: "api", fieldIdx: fieldAPIKey},
+		{label: "Base URL (Groq, OpenRouter, Ollama...)", groupID: "api", fieldIdx: fieldBaseURL},
+		{label: "Default Model", groupID: "api", fieldIdx: fieldModel},
+		{label: "Test Connection", hint: "Lists models and sends a tiny request with the values above", isTestBtn: true, groupID: "api", fieldIdx: -1},
+
+		{label: "Prompt & Context", isGroup: true, groupID: "prompt", fieldIdx: -1},
+		{label: "Custom Instructions", hint: "Enter adds a line", groupID: "prompt", fieldIdx: fieldCustomInstructions},
+		{label: "Send Directory Context", hint: "Sends your current working directory to the model for relevant answers", groupID: "prompt", fieldIdx: fieldIncludeCWD},
+		{label: "Send Shell History", hint: "Sends your most recent bash/zsh/fish commands as context", groupID: "prompt", fieldIdx: fieldIncludeHistory},
+		{label: "Shell History Lines", hint: "How many recent commands to send (default: 10)", groupID: "prompt", fieldIdx: fieldHistoryLines},
+		{label: "Send Git Branch", hint: "Sends the current branch when inside a git repository", groupID: "prompt", fieldIdx: fieldGitBranch},
+		{label: "Send Git Status", hint: "Sends a summary of git status --porcelain", groupID: "prompt", fieldIdx: fieldGitStatus},
+		{label: "Send Recent Commits", hint: "Sends the subjects of the latest commits", groupID: "prompt", fieldIdx: fieldGitLog},
+		{label: "Send Staged Diff", hint: "Sends git diff --cached (size-capped)", groupID: "prompt", fieldIdx: fieldGitDiff},
+
+		{label: "Display", isGroup: true, groupID: "display", fieldIdx: -1},
+		{label: "Max Response Lines", hint: "Lines shown before pager activates (default: 8)", groupID: "display", fieldIdx: fieldMaxResponseLines},
+		{label: "Clear Screen", groupID: "display", fieldIdx: fieldClearScreen},
+		{label: "Position", groupID: "display", fieldIdx: fieldPosition},
+		{label: "Accent Color (hex)", groupID: "display", fieldIdx: fieldAccentColor},
+		{label: "Raw Output", hint: "Show responses as plain text instead of rendered Markdown", groupID: "display", fieldIdx: fieldRawOutput},
+
+		{label: "Save & Exit", isSaveBtn: true, fieldIdx: -1},
 	}
 
 	return SettingsModel{
-		inputs:   inputs,
+		fields:   fields,
 		items:    items,
 		cursor:   0,
 		expanded: map[string]bool{"api": false, "prompt": false, "display": false},
 	}
 }
 
+// providerHint names the providers that run without an API key.
+func providerHint(providers []string) string {
+	var keyless []string
+	for _, name := range providers {
+		if !provider.RequiresAPIKey(name) {
+			keyless = append(keyless, name)
+		}
+	}
+	switch len(keyless) {
+	case 0:
+		return ""
+	case 1:
+		return keyless[0] + " needs no API key"
+	default:
+		return strings.Join(keyless, ", ") + " need no API key"
+	}
+}
+
 func (m SettingsModel) visibleItems() []int {
 	var visible []int
 	for i, item := range m.items {
@@ -168,114 +225,176 @@ func (m SettingsModel) visibleItems() []int {
 }
 
 func (m SettingsModel) Update(msg tea.Msg) (SettingsModel, tea.Cmd) {
+	if msg, ok := msg.(ConnectionResultMsg); ok {
+		m.testing = false
+		m.testResult = &msg
+		return m, nil
+	}
+
 	visible := m.visibleItems()
+	item := m.items[visible[m.cursor]]
+	var field settingsField
+	if item.fieldIdx >= 0 {
+		field = m.fields[item.fieldIdx]
+	}
 
-	switch msg := msg.(type) {
-	case tea.KeyMsg:
+	if msg, ok := msg.(tea.KeyMsg); ok && (field == nil || !field.Captures(msg.String())) {
 		switch msg.String() {
 		case "tab", "down":
-			item := m.items[visible[m.cursor]]
-			if !item.isGroup && item.inputIdx >= 0 {
-				m.inputs[item.inputIdx].Blur()
-			}
-			m.cursor = (m.cursor + 1) % len(visible)
-			return m, m.focusCurrent()
+			return m, m.moveCursor(1)
 
 		case "shift+tab", "up":
-			item := m.items[visible[m.cursor]]
-			if !item.isGroup && item.inputIdx >= 0 {
-				m.inputs[item.inputIdx].Blur()
-			}
-			m.cursor = (m.cursor - 1 + len(visible)) % len(visible)
-			return m, m.focusCurrent()
+			return m, m.moveCursor(-1)
 
 		case "enter":
-			item := m.items[visible[m.cursor]]
 			if item.isGroup {
 				m.expanded[item.groupID] = !m.expanded[item.groupID]
 				return m, nil
 			}
 			if item.isSaveBtn {
-				return m, m.buildSaveMsg()
+				return m.save()
 			}
-			// Otherwise advance to next
-			if item.inputIdx >= 0 {
-				m.inputs[item.inputIdx].Blur()
+			if item.isTestBtn {
+				return m.testConnection()
 			}
-			m.cursor++
-			if m.cursor >= len(visible) {
-				m.cursor = 0
+			return m, m.moveCursor(1)
+
+		case "m":
+			if item.isTestBtn && m.testResult != nil && len(m.testResult.Models) > 0 {
+				pick := SettingsPickModelMsg{Models: m.testResult.Models, Current: m.text(fieldModel)}
+				return m, func() tea.Msg { return pick }
 			}
-			return m, m.focusCurrent()
 		}
 	}
 
-	// Forward to active input
-	item := m.items[visible[m.cursor]]
-	if !item.isGroup && item.inputIdx >= 0 {
-		var cmd tea.Cmd
-		m.inputs[item.inputIdx], cmd = m.inputs[item.inputIdx].Update(msg)
-		return m, cmd
+	// Forward to the active field
+	if field != nil {
+		return m, field.Update(msg)
 	}
-
 	return m, nil
 }
 
+// moveCursor moves to the next (delta 1) or previous (-1) visible item.
+func (m *SettingsModel) moveCursor(delta int) tea.Cmd {
+	visible := m.visibleItems()
+	if idx := m.items[visible[m.cursor]].fieldIdx; idx >= 0 {
+		m.fields[idx].Blur()
+	}
+	m.cursor = (m.cursor + delta + len(visible)) % len(visible)
+	return m.focusCurrent()
+}
+
 func (m *SettingsModel) focusCurrent() tea.Cmd {
 	visible := m.visibleItems()
 	item := m.items[visible[m.cursor]]
-	if !item.isGroup && item.inputIdx >= 0 {
-		return m.inputs[item.inputIdx].Focus()
+	if item.fieldIdx >= 0 {
+		return m.fields[item.fieldIdx].Focus()
 	}
 	return nil
 }
 
-func (m SettingsModel) buildSaveMsg() tea.Cmd {
-	cwdVal := strings.TrimSpace(strings.ToLower(m.inputs[inputIncludeCWD].Value()))
-	includeCWD := cwdVal == "yes" || cwdVal == "y" || cwdVal == "true"
+// save emits SettingsSavedMsg, or moves to the first invalid field when
+// any value doesn't validate.
+func (m SettingsModel) save() (SettingsModel, tea.Cmd) {
+	for i, item := range m.items {
+		if item.fieldIdx < 0 || m.fields[item.fieldIdx].Validate() == nil {
+			continue
+		}
+		m.expanded[item.groupID] = true
+		for vi, idx := range m.visibleItems() {
+			if idx == i {
+				m.cursor = vi
+			}
+		}
+		m.saveErr = "fix " + item.label + " before saving"
+		return m, m.focusCurrent()
+	}
+	m.saveErr = ""
+
+	v := m.values()
+	return m, func() tea.Msg { return SettingsSavedMsg{v} }
+}
 
-	maxLines, err := strconv.Atoi(strings.TrimSpace(m.inputs[inputMaxResponseLines].Value()))
-	if err != nil || maxLines < 3 {
-		maxLines = 8
+// testConnection emits ConnectionTestMsg for the current values. The base
+// URL has to be valid for the test to mean anything.
+func (m SettingsModel) testConnection() (SettingsModel, tea.Cmd) {
+	if m.testing {
+		return m, nil
 	}
+	if err := m.fields[fieldBaseURL].Validate(); err != nil {
+		m.testResult = &ConnectionResultMsg{ListErr: "fix the Base URL first"}
+		return m, nil
+	}
+	m.testing = true
+	m.testResult = nil
+	v := m.values()
+	return m, func() tea.Msg { return ConnectionTestMsg{v} }
+}
 
-	clearVal := strings.TrimSpace(strings.ToLower(m.inputs[inputClearScreen].Value()))
-	clearScreen := clearVal == "yes" || clearVal == "y" || clearVal == "true"
+// SetModel fills the Default Model field, e.g. from the model picker.
+func (m *SettingsModel) SetModel(name string) {
+	m.fields[fieldModel].(*textField).input.SetValue(name)
+}
 
-	pos := strings.TrimSpace(strings.ToLower(m.inputs[inputPosition].Value()))
-	if pos != "top" {
-		pos = "bottom"
+// values collects the form into SettingsValues.
+func (m SettingsModel) values() SettingsValues {
+	return SettingsValues{
+		Provider:            m.text(fieldProvider),
+		APIKey:              m.fields[fieldAPIKey].(*textField).input.Value(),
+		BaseURL:             m.text(fieldBaseURL),
+		DefaultModel:        m.text(fieldModel),
+		CustomInstructions:  m.text(fieldCustomInstructions),
+		IncludeCWD:          m.on(fieldIncludeCWD),
+		IncludeShellHistory: m.on(fieldIncludeHistory),
+		ShellHistoryLines:   m.number(fieldHistoryLines),
+		IncludeGitBranch:    m.on(fieldGitBranch),
+		IncludeGitStatus:    m.on(fieldGitStatus),
+		IncludeGitLog:       m.on(fieldGitLog),
+		IncludeGitDiff:      m.on(fieldGitDiff),
+		MaxResponseLines:    m.number(fieldMaxResponseLines),
+		ClearScreen:         m.on(fieldClearScreen),
+		Position:            m.text(fieldPosition),
+		AccentColor:         m.text(fieldAccentColor),
+		RawOutput:           m.on(fieldRawOutput),
 	}
-	color := strings.TrimSpace(m.inputs[inputAccentColor].Value())
-
-	return func() tea.Msg {
-		return SettingsSavedMsg{
-			APIKey:             m.inputs[inputAPIKey].Value(),
-			BaseURL:            strings.TrimSpace(m.inputs[inputBaseURL].Value()),
-			DefaultModel:       m.inputs[inputModel].Value(),
-			CustomInstructions: strings.TrimSpace(m.inputs[inputCustomInstructions].Value()),
-			IncludeCWD:         includeCWD,
-			MaxResponseLines:   maxLines,
-			ClearScreen:        clearScreen,
-			Position:           pos,
-			AccentColor:        color,
-		}
+}
+
+// text returns the trimmed value of a text, select, color or multiline field.
+func (m SettingsModel) text(idx int) string {
+	switch f := m.fields[idx].(type) {
+	case *textField:
+		return f.Value()
+	case *colorField:
+		return f.Value()
+	case *selectField:
+		return f.Value()
+	case *textAreaField:
+		return f.Value()
 	}
+	return ""
+}
+
+func (m SettingsModel) on(idx int) bool {
+	return m.fields[idx].(*toggleField).on
+}
+
+func (m SettingsModel) number(idx int) int {
+	return m.fields[idx].(*stepperField).value
 }
 
 // groupSummary returns a short summary string for a collapsed group.
 func (m SettingsModel) groupSummary(groupID string) string {
 	switch groupID {
 	case "api":
-		model := m.inputs[inputModel].Value()
-		url := m.inputs[inputBaseURL].Value()
+		url := m.text(fieldBaseURL)
 		if url == "" {
-			url = "openai"
+			url = m.text(fieldProvider)
 		}
-		return fmt.Sprintf("%s @ %s", model, url)
+		return fmt.Sprintf("%s @ %s", m.text(fieldModel), url)
 	case "prompt":
 		parts := []string{}
-		if ci := m.inputs[inputCustomInstructions].Value(); ci != "" {
+		if ci := m.text(fieldCustomInstructions); ci != "" {
+			ci, _, _ = strings.Cut(ci, "\n")
 			if len(ci) > 30 {
 				ci = ci[:27] + "..."
 			}
@@ -283,23 +402,34 @@ func (m SettingsModel) groupSummary(groupID string) string {
 		} else {
 			parts = append(parts, "no custom instructions")
 		}
-		cwdVal := strings.TrimSpace(strings.ToLower(m.inputs[inputIncludeCWD].Value()))
-		if cwdVal == "yes" || cwdVal == "y" || cwdVal == "true" {
+		if m.on(fieldIncludeCWD) {
 			parts = append(parts, "dir context on")
 		} else {
 			parts = append(parts, "dir context off")
 		}
+		if m.on(fieldIncludeHistory) {
+			parts = append(parts, fmt.Sprintf("history: %d cmds", m.number(fieldHistoryLines)))
+		}
+		var git []string
+		for _, g := range []struct {
+			idx  int
+			name string
+		}{{fieldGitBranch, "branch"}, {fieldGitStatus, "status"}, {fieldGitLog, "log"}, {fieldGitDiff, "diff"}} {
+			if m.on(g.idx) {
+				git = append(git, g.name)
+			}
+		}
+		if len(git) > 0 {
+			parts = append(parts, "git: "+strings.Join(git, "/"))
+		}
 		return strings.Join(parts, " • ")
 	case "display":
-		clearVal := strings.TrimSpace(strings.ToLower(m.inputs[inputClearScreen].Value()))
-		pos := m.inputs[inputPosition].Value()
-		color := m.inputs[inputAccentColor].Value()
-		lines := m.inputs[inputMaxResponseLines].Value()
 		clear := "no"
-		if clearVal == "yes" || clearVal == "y" || clearVal == "true" {
+		if m.on(fieldClearScreen) {
 			clear = "yes"
 		}
-		return fmt.Sprintf("%s lines • clear: %s • %s • %s", lines, clear, pos, color)
+		return fmt.Sprintf("%d lines • clear: %s • %s • %s",
+			m.number(fieldMaxResponseLines), clear, m.text(fieldPosition), m.text(fieldAccentColor))
 	}
 	return ""
 }
@@ -320,6 +450,21 @@ func (m SettingsModel) View() string {
 			} else {
 				b.WriteString(DimStyle.Render("    [ "+item.label+" ]") + "\n")
 			}
+			if m.saveErr != "" {
+				b.WriteString(ErrorStyle.Render("    "+m.saveErr) + "\n")
+			}
+			continue
+		}
+
+		if item.isTestBtn {
+			if isCursor {
+				b.WriteString(SelectedStyle.Render("    ▸ [ "+item.label+" ]") + "\n")
+			} else {
+				b.WriteString(DimStyle.Render("      [ "+item.label+" ]") + "\n")
+			}
+			b.WriteString(DimStyle.Render("      "+item.hint) + "\n")
+			b.WriteString(m.testView(isCursor))
+			b.WriteString("\n")
 			continue
 		}
 
@@ -359,16 +504,61 @@ func (m SettingsModel) View() string {
 			b.WriteString(DimStyle.Render(indent+"  "+item.hint) + "\n")
 		}
 
-		b.WriteString(indent + "  " + m.inputs[item.inputIdx].View() + "\n\n")
+		field := m.fields[item.fieldIdx]
+		b.WriteString(indent + "  " + strings.ReplaceAll(field.View(isCursor), "\n", "\n"+indent+"  ") + "\n")
+		if err := field.Validate(); err != nil {
+			b.WriteString(ErrorStyle.Render(indent+"  "+err.Error()) + "\n")
+		}
+		b.WriteString("\n")
 	}
 
-	b.WriteString(DimStyle.Render("↑↓ navigate • enter expand/save • esc back"))
+	b.WriteString(DimStyle.Render("↑↓ navigate • ←/→ or space change • enter expand/save • esc back"))
 	return b.String()
 }
 
+// testView renders the state of the connection test under its button.
+func (m SettingsModel) testView(isCursor bool) string {
+	const indent = "      "
+	if m.testing {
+		return DimStyle.Render(indent+"testing...") + "\n"
+	}
+	r := m.testResult
+	if r == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	ok := func(s string) { b.WriteString(SelectedStyle.Render(indent+"✓ "+s) + "\n") }
+	fail := func(s string) { b.WriteString(ErrorStyle.Render(indent+"✗ "+s) + "\n") }
+
+	if r.ListErr != "" {
+		fail("list models: " + r.ListErr)
+	} else {
+		ok(fmt.Sprintf("listed %d models in %s", len(r.Models), latency(r.ListLatency)))
+	}
+	switch {
+	case r.ChatErr != "":
+		fail(r.Model + ": " + r.ChatErr)
+	case r.Model != "":
+		ok(fmt.Sprintf("%s replied in %s", r.Model, latency(r.ChatLatency)))
+	}
+	if r.ModelMissing {
+		b.WriteString(ErrorStyle.Render(indent+"! "+r.Model+" isn't among the listed models") + "\n")
+	}
+	if len(r.Models) > 0 && isCursor {
+		b.WriteString(DimStyle.Render(fmt.Sprintf("%spress m to pick the default model from the %d listed", indent, len(r.Models))) + "\n")
+	}
+	return b.String()
+}
+
+// latency formats d to millisecond precision.
+func latency(d time.Duration) string {
+	return d.Round(time.Millisecond).String()
+}
+
 func (m *SettingsModel) SetWidth(w int) {
 	m.width = w
-	for i := range m.inputs {
-		m.inputs[i].Width = w - 10
+	for _, f := range m.fields {
+		f.SetWidth(w - 10)
 	}
 }
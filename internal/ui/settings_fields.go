@@ -0,0 +1,288 @@
+package ui
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// settingsField is one editable value on the settings screen.
+type settingsField interface {
+	Update(msg tea.Msg) tea.Cmd
+	View(focused bool) string
+	Focus() tea.Cmd
+	Blur()
+	SetWidth(w int)
+	// Validate returns the message shown under an invalid value; saving
+	// is blocked while any field has one.
+	Validate() error
+	// Captures reports whether the field handles key itself rather than
+	// the form using it for navigation.
+	Captures(key string) bool
+}
+
+// textField is a single-line text input with optional validation.
+type textField struct {
+	input    textinput.Model
+	validate func(string) error
+}
+
+func newTextField(value, placeholder string, limit int, validate func(string) error) *textField {
+	ti := textinput.New()
+	ti.Placeholder = placeholder
+	ti.SetValue(value)
+	ti.CharLimit = limit
+	ti.Width = 50
+	return &textField{input: ti, validate: validate}
+}
+
+func (f *textField) Value() string { return strings.TrimSpace(f.input.Value()) }
+
+func (f *textField) Update(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	f.input, cmd = f.input.Update(msg)
+	return cmd
+}
+
+func (f *textField) View(bool) string     { return f.input.View() }
+func (f *textField) Focus() tea.Cmd       { return f.input.Focus() }
+func (f *textField) Blur()                { f.input.Blur() }
+func (f *textField) SetWidth(w int)       { f.input.Width = w }
+func (f *textField) Captures(string) bool { return false }
+
+func (f *textField) Validate() error {
+	if f.validate == nil {
+		return nil
+	}
+	return f.validate(f.Value())
+}
+
+// toggleField is an on/off switch flipped with space, enter or ←/→.
+type toggleField struct {
+	on bool
+}
+
+func (f *toggleField) Update(msg tea.Msg) tea.Cmd {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case " ", "enter", "left", "right", "h", "l":
+			f.on = !f.on
+		case "y":
+			f.on = true
+		case "n":
+			f.on = false
+		}
+	}
+	return nil
+}
+
+func (f *toggleField) View(bool) string {
+	if f.on {
+		return SelectedStyle.Render("● yes") + "  " + DimStyle.Render("○ no")
+	}
+	return DimStyle.Render("○ yes") + "  " + SelectedStyle.Render("● no")
+}
+
+func (f *toggleField) Focus() tea.Cmd           { return nil }
+func (f *toggleField) Blur()                    {}
+func (f *toggleField) SetWidth(int)             {}
+func (f *toggleField) Validate() error          { return nil }
+func (f *toggleField) Captures(key string) bool { return key == "enter" }
+
+// selectField picks one of a fixed set of options with ←/→.
+type selectField struct {
+	options []string
+	idx     int
+}
+
+func newSelectField(options []string, value string) *selectField {
+	f := &selectField{options: options}
+	for i, o := range options {
+		if o == value {
+			f.idx = i
+		}
+	}
+	return f
+}
+
+func (f *selectField) Value() string { return f.options[f.idx] }
+
+func (f *selectField) Update(msg tea.Msg) tea.Cmd {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "left", "h":
+			f.idx = (f.idx - 1 + len(f.options)) % len(f.options)
+		case "right", "l", " ":
+			f.idx = (f.idx + 1) % len(f.options)
+		}
+	}
+	return nil
+}
+
+func (f *selectField) View(focused bool) string {
+	parts := make([]string, len(f.options))
+	for i, o := range f.options {
+		if i == f.idx {
+			parts[i] = SelectedStyle.Render("[" + o + "]")
+		} else {
+			parts[i] = DimStyle.Render(" " + o + " ")
+		}
+	}
+	view := strings.Join(parts, " ")
+	if focused {
+		view += DimStyle.Render("  ←/→")
+	}
+	return view
+}
+
+func (f *selectField) Focus() tea.Cmd       { return nil }
+func (f *selectField) Blur()                {}
+func (f *selectField) SetWidth(int)         {}
+func (f *selectField) Validate() error      { return nil }
+func (f *selectField) Captures(string) bool { return false }
+
+// stepperField is a number kept within [min, max], changed with ←/→
+// (or -/+) or by typing digits.
+type stepperField struct {
+	value, min, max int
+	// typed holds digits entered since the field was focused.
+	typed string
+}
+
+func (f *stepperField) Update(msg tea.Msg) tea.Cmd {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+	switch s := key.String(); s {
+	case "left", "h", "-":
+		f.typed = ""
+		f.value = max(f.value-1, f.min)
+	case "right", "l", "+", "=":
+		f.typed = ""
+		f.value = min(f.value+1, f.max)
+	case "backspace":
+		if f.typed != "" {
+			f.typed = f.typed[:len(f.typed)-1]
+			if n, err := strconv.Atoi(f.typed); err == nil {
+				f.value = n
+			}
+		}
+	default:
+		if len(s) == 1 && s[0] >= '0' && s[0] <= '9' && len(f.typed) < len(strconv.Itoa(f.max)) {
+			f.typed += s
+			f.value, _ = strconv.Atoi(f.typed)
+		}
+	}
+	return nil
+}
+
+func (f *stepperField) View(focused bool) string {
+	view := fmt.Sprintf("‹ %d ›", f.value)
+	if focused {
+		return SelectedStyle.Render(view) + DimStyle.Render(fmt.Sprintf("  %d–%d, ←/→ or type", f.min, f.max))
+	}
+	return view
+}
+
+func (f *stepperField) Focus() tea.Cmd       { f.typed = ""; return nil }
+func (f *stepperField) Blur()                { f.typed = "" }
+func (f *stepperField) SetWidth(int)         {}
+func (f *stepperField) Captures(string) bool { return false }
+
+func (f *stepperField) Validate() error {
+	if f.value < f.min || f.value > f.max {
+		return fmt.Errorf("must be between %d and %d", f.min, f.max)
+	}
+	return nil
+}
+
+var hexColorRe = regexp.MustCompile(`^#(?:[0-9A-Fa-f]{3}|[0-9A-Fa-f]{6})$`)
+
+// colorField is a hex color input with a swatch previewing the value.
+type colorField struct {
+	textField
+}
+
+func newColorField(value string) *colorField {
+	return &colorField{*newTextField(value, "#FF6F61", 7, validateHexColor)}
+}
+
+func validateHexColor(s string) error {
+	if !hexColorRe.MatchString(s) {
+		return fmt.Errorf("use a hex color like #FF6F61")
+	}
+	return nil
+}
+
+func (f *colorField) View(focused bool) string {
+	swatch := DimStyle.Render("····")
+	if f.Validate() == nil {
+		swatch = lipgloss.NewStyle().Foreground(lipgloss.Color(f.Value())).Render("████")
+	}
+	return swatch + " " + f.input.View()
+}
+
+// textAreaField is a multiline editor. Enter inserts a newline; ↑/↓ leave
+// the field from its first and last lines.
+type textAreaField struct {
+	area textarea.Model
+}
+
+func newTextAreaField(value, placeholder string, limit int) *textAreaField {
+	ta := textarea.New()
+	ta.Placeholder = placeholder
+	ta.ShowLineNumbers = false
+	ta.Prompt = "┃ "
+	ta.CharLimit = limit
+	ta.SetWidth(50)
+	ta.SetHeight(4)
+	ta.SetValue(value)
+	return &textAreaField{area: ta}
+}
+
+func (f *textAreaField) Value() string { return strings.TrimSpace(f.area.Value()) }
+
+func (f *textAreaField) Update(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	f.area, cmd = f.area.Update(msg)
+	return cmd
+}
+
+func (f *textAreaField) View(bool) string { return f.area.View() }
+func (f *textAreaField) Focus() tea.Cmd   { return f.area.Focus() }
+func (f *textAreaField) Blur()            { f.area.Blur() }
+func (f *textAreaField) SetWidth(w int)   { f.area.SetWidth(w) }
+func (f *textAreaField) Validate() error  { return nil }
+
+func (f *textAreaField) Captures(key string) bool {
+	switch key {
+	case "enter":
+		return true
+	case "up":
+		return f.area.Line() > 0
+	case "down":
+		return f.area.Line() < f.area.LineCount()-1
+	}
+	return false
+}
+
+// validateBaseURL accepts an empty value (the provider default) or an
+// absolute http(s) URL.
+func validateBaseURL(s string) error {
+	if s == "" {
+		return nil
+	}
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("use an http:// or https:// URL, or leave empty for the default")
+	}
+	return nil
+}
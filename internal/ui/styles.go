@@ -19,6 +19,9 @@ var (
 	SpinnerStyle     lipgloss.Style
 	SelectedStyle    lipgloss.Style
 	StatusBarStyle   lipgloss.Style
+
+	SearchMatchStyle   lipgloss.Style
+	SearchCurrentStyle lipgloss.Style
 )
 
 func init() {
@@ -67,6 +70,14 @@ func applyAccent() {
 	StatusBarStyle = lipgloss.NewStyle().
 		Foreground(DimColor).
 		Italic(true)
+
+	SearchMatchStyle = lipgloss.NewStyle().
+		Reverse(true)
+
+	SearchCurrentStyle = lipgloss.NewStyle().
+		Background(AccentColor).
+		Foreground(lipgloss.Color("#000000")).
+		Bold(true)
 }
 
 // RenderBorderTitle renders a top border line with an embedded title.
@@ -1,24 +1,138 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/benji/cogito/internal/app"
+	"github.com/benji/cogito/internal/clipboard"
 	"github.com/benji/cogito/internal/config"
+	shellctx "github.com/benji/cogito/internal/context"
+	"github.com/benji/cogito/internal/shellinit"
 )
 
+// resumeFlag is a boolean-style flag that optionally takes a value:
+// "--resume" picks the latest session, "--resume=name" a specific one.
+// Boolean values ("--resume=false") switch it on or off like a bool flag.
+type resumeFlag struct {
+	set  bool
+	name string
+}
+
+func (f *resumeFlag) String() string   { return f.name }
+func (f *resumeFlag) IsBoolFlag() bool { return true }
+
+func (f *resumeFlag) Set(v string) error {
+	if on, err := strconv.ParseBool(v); err == nil {
+		f.set, f.name = on, ""
+		return nil
+	}
+	f.set, f.name = true, v
+	return nil
+}
+
 func main() {
-	cfg, err := config.Load()
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "shell-init":
+			os.Exit(runShellInit(os.Args[2:]))
+		case "fix":
+			os.Exit(runFix(os.Args[2:]))
+		case "commit":
+			os.Exit(runCommit(os.Args[2:]))
+		}
+	}
+
+	var resume resumeFlag
+	flag.Var(&resume, "resume", "resume the latest session, or `name` with --resume=name")
+	insertFile := flag.String("insert-file", "", "write the picked command to `path` and exit (used by shell-init widgets)")
+	prompt := flag.String("p", "", "answer `prompt` on stdout without the interactive UI (positional args work too)")
+	raw := flag.Bool("raw", false, "one-shot: stream the reply as plain text (the default)")
+	markdown := flag.Bool("markdown", false, "one-shot: render the reply as Markdown once it is complete")
+	profile := flag.String("profile", "", "use the settings of profile `name` from config.json")
+	flag.Parse()
+
+	cfg, err := loadConfig(*profile)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
 		os.Exit(1)
 	}
 
+	// Piped input is attached to the query. The TUI still works afterwards:
+	// Bubble Tea opens the terminal for keyboard input when stdin isn't one.
+	var attachment, attachmentNote string
+	if stdinIsPipe() {
+		data, truncated, err := readStdin()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading stdin: %v\n", err)
+			os.Exit(1)
+		}
+		if strings.TrimSpace(data) != "" {
+			attachment = shellctx.FormatAttachment("Piped input", data, truncated)
+			attachmentNote = "stdin (" + shellctx.FormatSize(len(data))
+			if truncated {
+				attachmentNote += ", truncated"
+			}
+			attachmentNote += ")"
+		}
+	}
+
+	query := strings.TrimSpace(*prompt)
+	if query == "" {
+		query = strings.TrimSpace(strings.Join(flag.Args(), " "))
+	}
+	if query != "" {
+		if resume.set {
+			fmt.Fprintln(os.Stderr, "Error: --resume opens the interactive UI and can't take a query; use --resume=name for a named session")
+			os.Exit(2)
+		}
+		if *raw && *markdown {
+			fmt.Fprintln(os.Stderr, "Error: --raw and --markdown are mutually exclusive")
+			os.Exit(2)
+		}
+		if attachment != "" {
+			query = attachment + "\n" + query
+		}
+		os.Exit(runOneShot(cfg, query, *markdown))
+	}
+
 	m := app.NewModel(cfg)
+	if attachment != "" {
+		m.Attach(attachmentNote, attachment)
+	}
+	if resume.set {
+		if err := m.ResumeSession(resume.name); err != nil {
+			fmt.Fprintf(os.Stderr, "Error resuming session: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *insertFile != "" {
+		m.SetInsertFile(*insertFile)
+	}
+
+	if _, err := runTUI(cfg, m); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// loadConfig loads the config and applies profile, if one is given.
+func loadConfig(profile string) (config.Config, error) {
+	cfg, err := config.Load()
+	if err != nil || profile == "" {
+		return cfg, err
+	}
+	return cfg.UseProfile(profile)
+}
 
+// runTUI starts the interactive Bubble Tea program and returns the model
+// it ended with.
+func runTUI(cfg config.Config, m app.Model) (app.Model, error) {
 	// When rendering at top without clearing, move cursor to top-left
 	// so Bubble Tea's inline renderer starts from position (1,1).
 	// Old terminal content below the box stays visible.
@@ -26,14 +140,31 @@ func main() {
 		fmt.Print("\033[H")
 	}
 
-	opts := []tea.ProgramOption{}
+	out := clipboard.NewOutput(os.Stdout)
+	m.SetOutput(out)
+	opts := []tea.ProgramOption{tea.WithOutput(out)}
 	if cfg.ClearScreen {
 		opts = append(opts, tea.WithAltScreen())
 	}
 	p := tea.NewProgram(m, opts...)
 
-	if _, err := p.Run(); err != nil {
+	final, err := p.Run()
+	if fm, ok := final.(app.Model); ok {
+		m = fm
+	}
+	return m, err
+}
+
+func runShellInit(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintf(os.Stderr, "usage: cogito shell-init <%s>\n", strings.Join(shellinit.Shells(), "|"))
+		return 2
+	}
+	script, err := shellinit.Script(args[0])
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		return 2
 	}
+	fmt.Print(script)
+	return 0
 }
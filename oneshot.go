@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"golang.org/x/term"
+
+	"github.com/benji/cogito/internal/config"
+	"github.com/benji/cogito/internal/oneshot"
+)
+
+// runOneShot streams the answer to query on stdout, or renders it as
+// Markdown once complete when markdown is set, and returns the exit code:
+// 0 on success, 1 on API errors, 130 when interrupted.
+func runOneShot(cfg config.Config, query string, markdown bool) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	opts := oneshot.Options{
+		Markdown: markdown,
+		Width:    80,
+		Notices:  os.Stderr,
+	}
+	if term.IsTerminal(int(os.Stdout.Fd())) {
+		if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && w > 0 {
+			opts.Width = w
+		}
+	}
+
+	if err := oneshot.Run(ctx, cfg, query, os.Stdout, opts); err != nil {
+		if errors.Is(err, context.Canceled) {
+			return 130
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
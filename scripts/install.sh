@@ -43,8 +43,5 @@ tar -xzf "$TMP_DIR/$TARBALL" -C "$TMP_DIR"
 
 install -m 0755 "$TMP_DIR/$BIN_NAME" "$INSTALL_DIR/$BIN_NAME"
 
-if [ -f "$TMP_DIR/launch-cogito.sh" ]; then
-  install -m 0755 "$TMP_DIR/launch-cogito.sh" "$INSTALL_DIR/launch-cogito.sh"
-fi
-
 echo "Installed $BIN_NAME to $INSTALL_DIR/$BIN_NAME"
+echo "For the Ctrl+G prompt widget, add to your shell rc: eval \"\$($BIN_NAME shell-init bash)\" (or zsh; fish: $BIN_NAME shell-init fish | source)"
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"io"
+	"os"
+)
+
+// maxStdinBytes caps how much piped input is attached to a query.
+const maxStdinBytes = 100 * 1024
+
+// stdinIsPipe reports whether stdin is redirected rather than a terminal.
+func stdinIsPipe() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice == 0
+}
+
+// readStdin reads piped input up to maxStdinBytes, reporting whether
+// more was available.
+func readStdin() (data string, truncated bool, err error) {
+	buf, err := io.ReadAll(io.LimitReader(os.Stdin, maxStdinBytes+1))
+	if err != nil {
+		return "", false, err
+	}
+	if len(buf) > maxStdinBytes {
+		return string(buf[:maxStdinBytes]), true, nil
+	}
+	return string(buf), false, nil
+}